@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// assetSpec describes an image the game can request
+type assetSpec struct {
+	name   string
+	width  int
+	height int
+	exact  bool // when false, width and height are the maximum size allowed (animation frames vary in size)
+}
+
+// AssetReport lists the problems found with the game assets
+type AssetReport struct {
+	Missing   []string
+	Unused    []string
+	WrongSize []string
+}
+
+// OK returns true when no asset is missing or wrongly sized. Unused assets are not considered an error.
+func (r AssetReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.WrongSize) == 0
+}
+
+// Write the report in a human readable form
+func (r AssetReport) Write(w io.Writer) {
+	for _, name := range r.Missing {
+		fmt.Fprintf(w, "missing: %s\n", name)
+	}
+	for _, message := range r.WrongSize {
+		fmt.Fprintf(w, "wrong size: %s\n", message)
+	}
+	for _, name := range r.Unused {
+		fmt.Fprintf(w, "unused: %s\n", name)
+	}
+	if r.OK() {
+		fmt.Fprintln(w, "assets OK")
+	}
+}
+
+// imageManifest returns every image name the game can request
+func imageManifest() []assetSpec {
+	specs := []assetSpec{
+		{name: "title", width: WindowWidth, height: WindowHeight, exact: true},
+		{name: "over", width: WindowWidth, height: WindowHeight, exact: true},
+		{name: "blank", width: 2, height: 2, exact: true},
+		{name: "bullet", width: 20, height: 65, exact: true},
+		{name: "life", width: 32, height: 32, exact: true},
+		{name: "font", width: 272, height: 92, exact: true},
+		{name: "powerup", width: 32, height: 32, exact: true},
+	}
+	// backgrounds: one per wave colour
+	for i := 0; i < 3; i++ {
+		specs = append(specs, assetSpec{name: fmt.Sprintf("bg%d", i), width: WindowWidth, height: WindowHeight, exact: true})
+	}
+	// title screen animation
+	for i := 0; i < 14; i++ {
+		specs = append(specs, assetSpec{name: fmt.Sprintf("space%d", i), width: WindowWidth, height: 64, exact: true})
+	}
+	for i := 0; i < 10; i++ {
+		specs = append(specs, assetSpec{name: fmt.Sprintf("digit%d", i), width: 30, height: 30, exact: true})
+	}
+	// segABCDE: fast, 2 health, head, direction (8) and leg frame (4)
+	for fast := 0; fast < 2; fast++ {
+		for health := 0; health < 2; health++ {
+			for head := 0; head < 2; head++ {
+				for direction := 0; direction < 8; direction++ {
+					for frame := 0; frame < 4; frame++ {
+						specs = append(specs, assetSpec{name: fmt.Sprintf("seg%d%d%d%d%d", fast, health, head, direction, frame), width: 80, height: 80})
+					}
+				}
+			}
+		}
+	}
+	// rockCTH: colour, type and health
+	for colour := 0; colour < 3; colour++ {
+		for rockType := 0; rockType < 4; rockType++ {
+			for health := 0; health < 5; health++ {
+				specs = append(specs, assetSpec{name: fmt.Sprintf("rock%d%d%d", colour, rockType, health), width: 48, height: 92})
+			}
+		}
+	}
+	// poisonTH: poisoned rock type and health
+	for rockType := 0; rockType < 4; rockType++ {
+		for health := 0; health < 5; health++ {
+			specs = append(specs, assetSpec{name: fmt.Sprintf("poison%d%d", rockType, health), width: 48, height: 92})
+		}
+	}
+	// meanieCF: colour and frame
+	for colour := 0; colour < 3; colour++ {
+		for frame := 0; frame < 3; frame++ {
+			specs = append(specs, assetSpec{name: fmt.Sprintf("meanie%d%d", colour, frame), width: 56, height: 66})
+		}
+	}
+	// expTF: explosion type and frame
+	for expType := 0; expType < 3; expType++ {
+		for frame := 0; frame < 8; frame++ {
+			specs = append(specs, assetSpec{name: fmt.Sprintf("exp%d%d", expType, frame), width: 110, height: 100})
+		}
+	}
+	// playerDF: direction and frame
+	for direction := 0; direction < 4; direction++ {
+		for frame := 0; frame < 3; frame++ {
+			specs = append(specs, assetSpec{name: fmt.Sprintf("player%d%d", direction, frame), width: 54, height: 80})
+		}
+	}
+	return specs
+}
+
+// soundManifest returns every sound name the game can request
+func soundManifest() []string {
+	names := []string{
+		"gameover",
+		"laser0",
+		"level_clear",
+		"meanie_explode0",
+		"player_explode0",
+		"rock_create0",
+		"rock_destroy0",
+		"segment_explode0",
+		"totem_destroy0",
+		"wave0",
+	}
+	for i := 0; i < 4; i++ {
+		names = append(names, fmt.Sprintf("hit%d", i))
+	}
+	return names
+}
+
+// validateAssets checks the images and sounds found in fsys against the manifests
+func validateAssets(fsys fs.FS) (AssetReport, error) {
+	report := AssetReport{}
+
+	imageNames, err := fs.Glob(fsys, "images/*.png")
+	if err != nil {
+		return report, err
+	}
+	found := make(map[string]string, len(imageNames))
+	for _, imageName := range imageNames {
+		found[assetName(imageName)] = imageName
+	}
+	for _, spec := range imageManifest() {
+		imageName, ok := found[spec.name]
+		if !ok {
+			report.Missing = append(report.Missing, "images/"+spec.name+".png")
+			continue
+		}
+		delete(found, spec.name)
+		width, height, err := imageSize(fsys, imageName)
+		if err != nil {
+			return report, err
+		}
+		if spec.exact && (width != spec.width || height != spec.height) {
+			report.WrongSize = append(report.WrongSize,
+				fmt.Sprintf("%s is %dx%d, expected %dx%d", imageName, width, height, spec.width, spec.height))
+		} else if width > spec.width || height > spec.height {
+			report.WrongSize = append(report.WrongSize,
+				fmt.Sprintf("%s is %dx%d, maximum is %dx%d", imageName, width, height, spec.width, spec.height))
+		}
+	}
+	for _, imageName := range found {
+		report.Unused = append(report.Unused, imageName)
+	}
+
+	soundNames, err := fs.Glob(fsys, "sounds/*.ogg")
+	if err != nil {
+		return report, err
+	}
+	found = make(map[string]string, len(soundNames))
+	for _, soundName := range soundNames {
+		found[assetName(soundName)] = soundName
+	}
+	for _, name := range soundManifest() {
+		if _, ok := found[name]; !ok {
+			report.Missing = append(report.Missing, "sounds/"+name+".ogg")
+			continue
+		}
+		delete(found, name)
+	}
+	for _, soundName := range found {
+		report.Unused = append(report.Unused, soundName)
+	}
+
+	sort.Strings(report.Unused)
+	return report, nil
+}
+
+// imageSize reads the dimensions of an image without decoding it
+func imageSize(fsys fs.FS, imageName string) (int, int, error) {
+	file, err := fsys.Open(imageName)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", imageName, err)
+	}
+	return config.Width, config.Height, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedAssets(t *testing.T) {
+	report, err := validateAssets(embeddedFiles)
+	require.NoError(t, err)
+	assert.Empty(t, report.Missing)
+	assert.Empty(t, report.WrongSize)
+	assert.True(t, report.OK())
+}
+
+func TestValidateAssets(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, spec := range imageManifest() {
+		fsys["images/"+spec.name+".png"] = &fstest.MapFile{Data: encodePNG(t, spec.width, spec.height)}
+	}
+	for _, name := range soundManifest() {
+		fsys["sounds/"+name+".ogg"] = &fstest.MapFile{}
+	}
+	delete(fsys, "images/seg11173.png")
+	delete(fsys, "sounds/hit3.ogg")
+	fsys["images/rock000.png"] = &fstest.MapFile{Data: encodePNG(t, 50, 50)}
+	fsys["images/title.png"] = &fstest.MapFile{Data: encodePNG(t, 480, 799)}
+	fsys["images/extra.png"] = &fstest.MapFile{Data: encodePNG(t, 1, 1)}
+
+	report, err := validateAssets(fsys)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.ElementsMatch(t, []string{"images/seg11173.png", "sounds/hit3.ogg"}, report.Missing)
+	assert.Equal(t, []string{"images/extra.png"}, report.Unused)
+	assert.Len(t, report.WrongSize, 2)
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	buffer := &bytes.Buffer{}
+	err := png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, width, height)))
+	require.NoError(t, err)
+	return buffer.Bytes()
+}
@@ -0,0 +1,105 @@
+package main
+
+// Board is the part of the game state belonging to one player in an alternating game:
+// the rock field, the myriapod and the wave reached. Each player resumes their board where they left it.
+type Board struct {
+	player      *Player
+	grid        [][]*Rock
+	wave        int
+	time        int
+	segments    []*Segment
+	boss        *Boss
+	poisonTrail map[Cell]int
+}
+
+// NewBoard creates an empty board for the player, before the first wave
+func NewBoard(player *Player) *Board {
+	board := &Board{
+		player:      player,
+		wave:        -1,
+		segments:    make([]*Segment, 0, 20),
+		poisonTrail: make(map[Cell]int),
+	}
+	board.grid = make([][]*Rock, NumGridRows)
+	for i := range board.grid {
+		board.grid[i] = make([]*Rock, NumGridCols)
+	}
+	return board
+}
+
+// saveBoard keeps the current state of the game into the board
+func (g *Game) saveBoard(board *Board) {
+	board.grid = g.grid
+	board.wave = g.wave
+	board.time = g.time
+	board.segments = g.segments
+	board.boss = g.boss
+	board.poisonTrail = g.poisonTrail
+}
+
+// loadBoard puts the board back into play, with its player
+func (g *Game) loadBoard(board *Board) {
+	g.grid = board.grid
+	g.wave = board.wave
+	g.time = board.time
+	g.segments = board.segments
+	g.boss = board.boss
+	g.poisonTrail = board.poisonTrail
+	g.players = []*Player{board.player}
+}
+
+// Alternating returns true in a two player game where the players take turns
+func (g *Game) Alternating() bool {
+	return len(g.boards) > 0
+}
+
+// allPlayers returns the players in play and the players waiting for their turn
+func (g *Game) allPlayers() []*Player {
+	if !g.Alternating() {
+		return g.players
+	}
+	players := make([]*Player, len(g.boards))
+	for i, board := range g.boards {
+		players[i] = board.player
+	}
+	return players
+}
+
+// checkTurn passes the turn to the next player still in the game, once the current player has lost a life
+func (g *Game) checkTurn() {
+	player := g.players[0]
+	if player.alive || player.timer != RespawnTime {
+		return
+	}
+	for i := 1; i < len(g.boards); i++ {
+		next := (g.turn + i) % len(g.boards)
+		if g.Lives(g.boards[next].player) > 0 {
+			g.switchBoard(next)
+			return
+		}
+	}
+}
+
+// switchBoard stores the current board and brings the next one, behind the "PLAYER n" screen
+func (g *Game) switchBoard(next int) {
+	g.saveBoard(g.boards[g.turn])
+	g.turn = next
+	g.loadBoard(g.boards[next])
+
+	// nothing in flight carries over to the other board
+	for _, bullet := range g.bullets {
+		bullet.done = true
+	}
+	for _, powerUp := range g.powerUps {
+		powerUp.done = true
+	}
+	for _, enemy := range g.enemies {
+		enemy.Destroy()
+	}
+	g.particles.Clear()
+	g.effects.Reset()
+	g.hud.Reset()
+
+	g.state = StateNextPlayer
+	g.stateTimer = NextPlayerTime
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/particle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAlternatingGame() *Game {
+	game := &Game{
+		particles: particle.NewSystem(10),
+		effects:   NewEffects(),
+		state:     StatePlaying,
+	}
+	game.hud = &HUD{game: game}
+	game.boards = []*Board{
+		NewBoard(&Player{game: game, index: 0, lives: PlayerLives, alive: true}),
+		NewBoard(&Player{game: game, index: 1, lives: PlayerLives, alive: true}),
+	}
+	game.loadBoard(game.boards[0])
+	return game
+}
+
+func TestBoardsKeepTheirState(t *testing.T) {
+	game := newAlternatingGame()
+	require.True(t, game.Alternating())
+	player1 := game.players[0]
+
+	// play a bit on the first board
+	game.wave = 3
+	game.time = 42
+	game.grid[5][6] = &Rock{}
+	segment := &Segment{}
+	game.segments = append(game.segments, segment)
+
+	game.switchBoard(1)
+	assert.Equal(t, StateNextPlayer, game.state)
+	assert.Equal(t, 1, game.turn)
+	assert.Same(t, game.boards[1].player, game.players[0])
+	assert.Equal(t, -1, game.wave)
+	assert.Equal(t, 0, game.time)
+	assert.Nil(t, game.grid[5][6])
+	assert.Empty(t, game.segments)
+
+	game.switchBoard(0)
+	assert.Same(t, player1, game.players[0])
+	assert.Equal(t, 3, game.wave)
+	assert.Equal(t, 42, game.time)
+	assert.NotNil(t, game.grid[5][6])
+	require.Len(t, game.segments, 1)
+	assert.Same(t, segment, game.segments[0])
+}
+
+func TestCheckTurn(t *testing.T) {
+	game := newAlternatingGame()
+	player1, player2 := game.boards[0].player, game.boards[1].player
+
+	// still alive: no change
+	game.checkTurn()
+	assert.Equal(t, 0, game.turn)
+
+	// lost a life: next player
+	player1.alive = false
+	player1.timer = RespawnTime
+	game.checkTurn()
+	assert.Equal(t, 1, game.turn)
+	assert.Same(t, player2, game.players[0])
+
+	// the other player has no life left: keep playing
+	player1.lives = 0
+	player2.alive = false
+	player2.timer = RespawnTime
+	game.state = StatePlaying
+	game.checkTurn()
+	assert.Equal(t, 1, game.turn)
+	assert.Equal(t, StatePlaying, game.state)
+
+	assert.False(t, game.IsGameOver())
+	player2.lives = 0
+	assert.True(t, game.IsGameOver())
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// BossPhase starts when the boss is down to a number of segments
+type BossPhase struct {
+	Segments int    `json:"segments"` // the phase starts when this many segments (or fewer) are left
+	Fast     bool   `json:"fast"`
+	Announce string `json:"announce"`
+}
+
+// BossDefinition describes a boss wave
+type BossDefinition struct {
+	Wave          int          `json:"wave"`
+	Every         int          `json:"every"` // the boss comes back every n waves after the first one (0 = only once)
+	Name          string       `json:"name"`
+	Segments      int          `json:"segments"`
+	SegmentHealth int          `json:"segmentHealth"`
+	HeadHealth    int          `json:"headHealth"`
+	Armoured      bool         `json:"armoured"` // the body segments deflect the bullets hitting their front
+	Reward        int          `json:"reward"`
+	RewardPowerUp *PowerUpType `json:"rewardPowerUp"`
+	Phases        []BossPhase  `json:"phases"`
+}
+
+// IsWave returns true if the boss comes on this wave
+func (d *BossDefinition) IsWave(wave int) bool {
+	if wave == d.Wave {
+		return true
+	}
+	return d.Every > 0 && wave > d.Wave && (wave-d.Wave)%d.Every == 0
+}
+
+// Phase returns the index of the phase for the number of segments left, or -1 if there's no phase
+func (d *BossDefinition) Phase(segments int) int {
+	phase := -1
+	for i, definition := range d.Phases {
+		if segments <= definition.Segments {
+			phase = i
+		}
+	}
+	return phase
+}
+
+// BossConfig lists the boss waves
+type BossConfig struct {
+	Bosses []BossDefinition `json:"bosses"`
+}
+
+// LoadBossConfig reads the JSON configuration
+func LoadBossConfig(data []byte) (*BossConfig, error) {
+	config := &BossConfig{}
+	err := json.Unmarshal(data, config)
+	if err != nil {
+		return nil, err
+	}
+	for i := range config.Bosses {
+		boss := &config.Bosses[i]
+		if boss.Segments < 1 || boss.SegmentHealth < 1 || boss.HeadHealth < 1 {
+			return nil, fmt.Errorf("boss %q: segments, segmentHealth and headHealth must be at least 1", boss.Name)
+		}
+		// phases are expected from the most segments to the fewest
+		sort.SliceStable(boss.Phases, func(i, j int) bool { return boss.Phases[i].Segments > boss.Phases[j].Segments })
+	}
+	return config, nil
+}
+
+// ForWave returns the boss coming on this wave, if any
+func (c *BossConfig) ForWave(wave int) *BossDefinition {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Bosses {
+		if c.Bosses[i].IsWave(wave) {
+			return &c.Bosses[i]
+		}
+	}
+	return nil
+}
+
+// Boss follows the state of a boss myriapod: its phase, and the reward once all the segments are destroyed
+type Boss struct {
+	game       *Game
+	definition *BossDefinition
+	head       *Segment
+	phase      int
+}
+
+// armourDeflects returns true when a bullet moving by (dx, dy) hits the armoured front of a segment facing
+// this direction (0 = up, 1 = top right, up to 7 = top left)
+func armourDeflects(facing Direction, dx, dy float64) bool {
+	// side of the segment hit by the bullet: opposite to the way the bullet is going
+	angle := math.Atan2(-dx, dy)
+	side := int(math.Round(angle/(math.Pi/4))+8) % 8
+	difference := (side - int(facing) + 8) % 8
+	return difference <= ArmourArc || difference >= 8-ArmourArc
+}
+
+// StartBoss creates the boss myriapod
+func (g *Game) StartBoss(definition *BossDefinition) {
+	g.boss = &Boss{
+		game:       g,
+		definition: definition,
+		phase:      definition.Phase(definition.Segments),
+	}
+	for i := 0; i < definition.Segments; i++ {
+		head := i == 0
+		health := definition.SegmentHealth
+		if head {
+			health = definition.HeadHealth
+		}
+		segment := NewSegment(g, -1-i, 0, health, false, head)
+		segment.boss = true
+		segment.armoured = definition.Armoured && !head
+		segment.sprite.SetScale(BossScale, BossScale)
+		if head {
+			segment.sprite.SetTint(color.RGBA{0xff, 0x80, 0x80, 0xff})
+			g.boss.head = segment
+		} else if segment.armoured {
+			segment.sprite.SetTint(color.RGBA{0xb0, 0xb0, 0xd0, 0xff})
+		}
+		g.segments = append(g.segments, segment)
+	}
+	g.hud.Announce(definition.Name)
+}
+
+// Fast returns true when the current phase makes the boss move faster
+func (b *Boss) Fast() bool {
+	return b.phase >= 0 && b.definition.Phases[b.phase].Fast
+}
+
+// HeadHealth returns the health left on the head, between 0 and 1
+func (b *Boss) HeadHealth() float64 {
+	if b.head.health <= 0 {
+		return 0
+	}
+	return float64(b.head.health) / float64(b.definition.HeadHealth)
+}
+
+// Segments returns the number of boss segments left. The segments sent by a versus opponent during
+// the boss wave don't count.
+func (b *Boss) Segments() int {
+	count := 0
+	for _, segment := range b.game.segments {
+		if segment.boss {
+			count++
+		}
+	}
+	return count
+}
+
+// Update checks for a phase change, and gives the reward once the boss is destroyed.
+// It returns true when the boss is destroyed.
+func (b *Boss) Update() bool {
+	remaining := b.Segments()
+	if remaining == 0 {
+		x, y := WindowWidth/2, WindowHeight/2+40
+		// every player still in the game gets the reward
+		for _, player := range b.game.players {
+			if !player.IsOut() {
+				b.game.AddScoreAt(player, b.definition.Reward, x, y)
+			}
+		}
+		if b.definition.RewardPowerUp != nil {
+			b.game.findAvailablePowerUp().Start(x, y, *b.definition.RewardPowerUp)
+		}
+		b.game.SoundEffect("level_clear")
+		b.game.hud.Announce("BONUS " + strconv.Itoa(b.definition.Reward))
+		return true
+	}
+	phase := b.definition.Phase(remaining)
+	if phase != b.phase {
+		b.phase = phase
+		if phase >= 0 && b.definition.Phases[phase].Announce != "" {
+			b.game.hud.Announce(b.definition.Phases[phase].Announce)
+			b.game.effects.Shake(20, 6)
+		}
+	}
+	return false
+}
+
+func (g *Game) updateBoss() {
+	if g.boss != nil && g.boss.Update() {
+		g.boss = nil
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBossConfig(t *testing.T) {
+	data, err := embeddedFiles.ReadFile("data/bosses.json")
+	require.NoError(t, err)
+	config, err := LoadBossConfig(data)
+	require.NoError(t, err)
+	require.NotEmpty(t, config.Bosses)
+
+	_, err = LoadBossConfig([]byte(`{"bosses": [{"name": "empty"}]}`))
+	assert.Error(t, err)
+	_, err = LoadBossConfig([]byte(`{"bosses": [{"segments": 1, "segmentHealth": 1, "headHealth": 1, "rewardPowerUp": "laser"}]}`))
+	assert.Error(t, err)
+}
+
+func TestBossForWave(t *testing.T) {
+	config := &BossConfig{Bosses: []BossDefinition{
+		{Name: "once", Wave: 2},
+		{Name: "repeat", Wave: 5, Every: 3},
+	}}
+	testCases := []struct {
+		wave     int
+		expected string
+	}{
+		{0, ""},
+		{2, "once"},
+		{3, ""},
+		{5, "repeat"},
+		{6, ""},
+		{8, "repeat"},
+		{11, "repeat"},
+	}
+	for _, testCase := range testCases {
+		boss := config.ForWave(testCase.wave)
+		if testCase.expected == "" {
+			assert.Nil(t, boss, "wave %d", testCase.wave)
+			continue
+		}
+		require.NotNil(t, boss, "wave %d", testCase.wave)
+		assert.Equal(t, testCase.expected, boss.Name)
+	}
+
+	var none *BossConfig
+	assert.Nil(t, none.ForWave(2))
+}
+
+func TestBossPhase(t *testing.T) {
+	config, err := LoadBossConfig([]byte(`{"bosses": [{"segments": 16, "segmentHealth": 1, "headHealth": 1,
+		"phases": [{"segments": 4}, {"segments": 16}, {"segments": 10}]}]}`))
+	require.NoError(t, err)
+	boss := &config.Bosses[0]
+
+	testCases := []struct {
+		segments int
+		phase    int
+	}{
+		{20, -1},
+		{16, 0},
+		{11, 0},
+		{10, 1},
+		{5, 1},
+		{4, 2},
+		{1, 2},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.phase, boss.Phase(testCase.segments), "%d segments", testCase.segments)
+	}
+}
+
+func TestArmourDeflects(t *testing.T) {
+	testCases := []struct {
+		name     string
+		facing   Direction
+		dx, dy   float64
+		expected bool
+	}{
+		{"facing down", 4, 0, -24, true},
+		{"facing down right", 3, 0, -24, true},
+		{"facing down left", 5, 0, -24, true},
+		{"facing right", 2, 0, -24, false},
+		{"facing up", 0, 0, -24, false},
+		{"spread facing down", 4, 4, -24, true},
+		{"from the side", 2, -24, 0, true},
+		{"from behind", 0, -24, 0, false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, armourDeflects(testCase.facing, testCase.dx, testCase.dy))
+		})
+	}
+}
+
+func TestBossRewardedAfterSmartBomb(t *testing.T) {
+	game := newHeadlessTestGame(t, 1, 1)
+	game.wave = 4
+	// plenty of rocks: a new wave would start as soon as there's no segment left
+	for y := 1; y < 10; y++ {
+		for x := 0; x < NumGridCols; x++ {
+			if game.grid[y][x] == nil {
+				game.grid[y][x] = NewRock(game, x, y, false)
+			}
+		}
+	}
+	game.StartBoss(&BossDefinition{Name: "test", Segments: 3, SegmentHealth: 1, HeadHealth: 1, Reward: 500})
+	// all the segments in the player zone
+	for i, segment := range game.segments {
+		segment.cx, segment.cy = i, NumGridRows-4
+	}
+	player := game.players[0]
+	game.findAvailablePowerUp().Start(player.sprite.X(lib.XCentre), player.sprite.Y(lib.YCentre), PowerUpSmartBomb)
+
+	game.step()
+	assert.Nil(t, game.boss)
+	assert.Equal(t, 4, game.wave)
+	assert.GreaterOrEqual(t, player.score, 3*10+500)
+}
+
+func TestBossIgnoresAttackSegments(t *testing.T) {
+	game := newHeadlessTestGame(t, 1, 1)
+	game.StartBoss(&BossDefinition{Name: "test", Segments: 4, SegmentHealth: 1, HeadHealth: 1, Reward: 500,
+		Phases: []BossPhase{{Segments: 4}, {Segments: 1, Fast: true}}})
+	game.boss.Update()
+	require.Equal(t, 0, game.boss.phase)
+
+	// a versus opponent sends segments, and the boss is down to its head
+	game.segments = game.segments[:1]
+	game.segments = append(game.segments, NewSegment(game, -1, 0, 1, false, true), NewSegment(game, -2, 0, 1, false, false))
+	assert.False(t, game.boss.Update())
+	assert.Equal(t, 1, game.boss.phase)
+
+	// the boss is destroyed, even with the other segments still there
+	game.segments = game.segments[1:]
+	assert.True(t, game.boss.Update())
+	assert.Equal(t, 500, game.players[0].score)
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
-	"math/rand"
+	"strconv"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type Bullet struct {
-	game   *Game
-	sprite *lib.Sprite
-	done   bool
+	game    *Game
+	owner   *Player // bullets never hit players, including the other player in co-op
+	sprite  *lib.Sprite
+	shot    Shot
+	lastHit *Segment // a piercing bullet only damages each segment once
+	done    bool
 }
 
 func NewBullet(game *Game) *Bullet {
@@ -21,36 +24,50 @@ func NewBullet(game *Game) *Bullet {
 	}
 }
 
-func (b *Bullet) Start(x, y float64) {
+func (b *Bullet) Start(owner *Player, x, y float64, shot Shot) {
 	b.done = false
-	b.sprite.MoveTo(x, y)
+	b.owner = owner
+	b.shot = shot
+	b.lastHit = nil
+	b.sprite.MoveTo(x, y).SetScale(shot.Scale, shot.Scale).SetTint(shot.Colour)
 }
 
 func (b *Bullet) IsDone() bool {
 	return b.done
 }
 
+// refreshImages is called after the images have been reloaded
+func (b *Bullet) refreshImages() {
+	b.sprite.SetImage(images["bullet"])
+}
+
 func (b *Bullet) Update() {
 	if b.done {
 		return
 	}
 
-	b.sprite.Move(0, -24)
+	b.sprite.Move(b.shot.DX, -b.shot.Speed)
 
 	x := b.sprite.X(lib.XCentre)
 	y := b.sprite.Y(lib.YCentre)
 
-	if y <= 0 {
+	if y <= 0 || x < 0 || x > WindowWidth {
 		b.done = true
+		return
 	}
 	cellX, cellY := PosToCell(x, y)
-	if b.game.Damage(cellX, cellY, 1, true) {
+	if cellX < 0 || cellX >= NumGridCols {
+		// in the border on each side of the grid: a diagonal bullet goes through before leaving the screen
+		b.done = true
+		return
+	}
+	if b.game.Damage(cellX, cellY, b.shot.Damage, b.owner) {
 		// Hit a rock - destroy self
 		b.done = true
 		return
 	}
-	if b.game.enemy.Collision(x, y) {
-		b.game.AddScore(20)
+	if enemy := b.game.EnemyCollision(x, y); enemy != nil {
+		b.game.AddScoreAt(b.owner, enemy.Score(), x, y)
 		b.game.SoundEffect("meanie_explode0")
 		b.game.Explosion(x, y, 2)
 		b.done = true
@@ -58,15 +75,30 @@ func (b *Bullet) Update() {
 	}
 
 	for i := 0; i < len(b.game.segments); i++ {
-		if b.game.segments[i].Collision(x, y) {
-			b.game.AddScore(10)
+		if b.game.segments[i] == b.lastHit {
+			continue
+		}
+		if b.game.segments[i].Deflects(x, y, b.shot.DX, -b.shot.Speed) {
+			b.game.SoundEffect("hit" + strconv.Itoa(b.game.random.Intn(4)))
+			b.done = true
+			return
+		}
+		if b.game.segments[i].Collision(x, y, b.shot.Damage) {
+			b.game.AddScoreAt(b.owner, 10, x, y)
 			b.game.SoundEffect("segment_explode0")
 			b.game.Explosion(x, y, 2)
-			b.done = true
-			if b.game.segments[i].health == 0 {
-				if b.game.grid[cellY][cellX] == nil && b.game.AllowPlayerMovement2(b.game.player.sprite.X(lib.XCentre), b.game.player.sprite.Y(lib.YCentre), cellX, cellY) {
+			b.lastHit = b.game.segments[i]
+			if b.shot.Pierce > 0 {
+				b.shot.Pierce--
+			} else {
+				b.done = true
+			}
+			if b.game.segments[i].health <= 0 {
+				b.game.Particles(x, y, segmentSplat)
+				b.game.SegmentKilled(x, y)
+				if b.game.grid[cellY][cellX] == nil && b.game.AllowRock(cellX, cellY) {
 					// Create new rock - 20% chance of being a totem
-					b.game.grid[cellY][cellX] = NewRock(b.game, cellX, cellY, rand.Float64() < .2)
+					b.game.grid[cellY][cellX] = NewRock(b.game, cellX, cellY, b.game.random.Float64() < .2)
 				}
 				b.game.segments[i] = nil
 				b.game.segments = append(b.game.segments[:i], b.game.segments[i+1:]...)
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Controls reads the input of one player
+type Controls interface {
+	Left() bool
+	Right() bool
+	Up() bool
+	Down() bool
+	Fire() bool
+}
+
+// KeyboardControls reads a set of keys
+type KeyboardControls struct {
+	LeftKey  ebiten.Key
+	RightKey ebiten.Key
+	UpKey    ebiten.Key
+	DownKey  ebiten.Key
+	FireKey  ebiten.Key
+}
+
+func (k KeyboardControls) Left() bool  { return ebiten.IsKeyPressed(k.LeftKey) }
+func (k KeyboardControls) Right() bool { return ebiten.IsKeyPressed(k.RightKey) }
+func (k KeyboardControls) Up() bool    { return ebiten.IsKeyPressed(k.UpKey) }
+func (k KeyboardControls) Down() bool  { return ebiten.IsKeyPressed(k.DownKey) }
+func (k KeyboardControls) Fire() bool  { return ebiten.IsKeyPressed(k.FireKey) }
+
+// GamepadControls reads a standard gamepad, by its index in the list of connected gamepads
+type GamepadControls struct {
+	Index int
+	ids   []ebiten.GamepadID
+}
+
+func (g *GamepadControls) id() (ebiten.GamepadID, bool) {
+	g.ids = ebiten.AppendGamepadIDs(g.ids[:0])
+	if g.Index >= len(g.ids) || !ebiten.IsStandardGamepadLayoutAvailable(g.ids[g.Index]) {
+		return 0, false
+	}
+	return g.ids[g.Index], true
+}
+
+func (g *GamepadControls) pressed(button ebiten.StandardGamepadButton) bool {
+	id, ok := g.id()
+	return ok && ebiten.IsStandardGamepadButtonPressed(id, button)
+}
+
+func (g *GamepadControls) axis(axis ebiten.StandardGamepadAxis) float64 {
+	id, ok := g.id()
+	if !ok {
+		return 0
+	}
+	return ebiten.StandardGamepadAxisValue(id, axis)
+}
+
+func (g *GamepadControls) Left() bool {
+	return g.pressed(ebiten.StandardGamepadButtonLeftLeft) || g.axis(ebiten.StandardGamepadAxisLeftStickHorizontal) < -GamepadDeadZone
+}
+
+func (g *GamepadControls) Right() bool {
+	return g.pressed(ebiten.StandardGamepadButtonLeftRight) || g.axis(ebiten.StandardGamepadAxisLeftStickHorizontal) > GamepadDeadZone
+}
+
+func (g *GamepadControls) Up() bool {
+	return g.pressed(ebiten.StandardGamepadButtonLeftTop) || g.axis(ebiten.StandardGamepadAxisLeftStickVertical) < -GamepadDeadZone
+}
+
+func (g *GamepadControls) Down() bool {
+	return g.pressed(ebiten.StandardGamepadButtonLeftBottom) || g.axis(ebiten.StandardGamepadAxisLeftStickVertical) > GamepadDeadZone
+}
+
+func (g *GamepadControls) Fire() bool {
+	return g.pressed(ebiten.StandardGamepadButtonRightBottom)
+}
+
+// AnyControls combines several controls: an action is on when it's on for any of them
+type AnyControls []Controls
+
+func (a AnyControls) Left() bool  { return a.any(Controls.Left) }
+func (a AnyControls) Right() bool { return a.any(Controls.Right) }
+func (a AnyControls) Up() bool    { return a.any(Controls.Up) }
+func (a AnyControls) Down() bool  { return a.any(Controls.Down) }
+func (a AnyControls) Fire() bool  { return a.any(Controls.Fire) }
+
+func (a AnyControls) any(action func(Controls) bool) bool {
+	for _, controls := range a {
+		if action(controls) {
+			return true
+		}
+	}
+	return false
+}
+
+// playerControls returns the controls of each player: the keyboard (arrows and space for player 1,
+// IJKL and enter for player 2) or a gamepad
+func playerControls() [2]Controls {
+	return [2]Controls{
+		AnyControls{
+			KeyboardControls{ebiten.KeyArrowLeft, ebiten.KeyArrowRight, ebiten.KeyArrowUp, ebiten.KeyArrowDown, ebiten.KeySpace},
+			&GamepadControls{Index: 0},
+		},
+		AnyControls{
+			KeyboardControls{ebiten.KeyJ, ebiten.KeyL, ebiten.KeyI, ebiten.KeyK, ebiten.KeyEnter},
+			&GamepadControls{Index: 1},
+		},
+	}
+}
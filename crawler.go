@@ -0,0 +1,95 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Crawler walks horizontally across the upper part of the screen, poisoning every rock it touches
+type Crawler struct {
+	game   *Game
+	sprite *lib.Sprite
+	dx     float64
+	health int
+}
+
+func NewCrawler(game *Game) *Crawler {
+	return &Crawler{
+		game:   game,
+		sprite: lib.NewSprite(lib.XCentre, lib.YCentre).SetAnimations(animations).SetTint(color.RGBA{0xc0, 0x60, 0xff, 0xff}),
+	}
+}
+
+// CanSpawn gives a small chance every frame, from the third wave
+func (e *Crawler) CanSpawn() bool {
+	return e.game.wave >= CrawlerMinWave && e.game.random.Float64() < .002
+}
+
+// Score returns the number of points for destroying the enemy
+func (e *Crawler) Score() int {
+	return 1000
+}
+
+func (e *Crawler) Start() {
+	cellY := CrawlerMinRow + e.game.random.Intn(CrawlerMaxRow-CrawlerMinRow+1)
+	_, y := CellToPos(0, cellY, 0, 0)
+	side := e.game.random.Intn(2)
+	x := -32.0
+	e.dx = 2
+	if side == 1 {
+		x = WindowWidth + 32
+		e.dx = -2
+	}
+	e.sprite.MoveTo(x, y).SetFlip(e.dx < 0, false).Play("crawler")
+	e.health = 1
+}
+
+// Position returns the centre of the enemy
+func (e *Crawler) Position() (float64, float64) {
+	return e.sprite.X(lib.XCentre), e.sprite.Y(lib.YCentre)
+}
+
+// Destroy the enemy at once
+func (e *Crawler) Destroy() {
+	e.health = 0
+}
+
+func (e *Crawler) IsInactive() bool {
+	x := e.sprite.X(lib.XCentre)
+	return e.health <= 0 || x < -40 || x > WindowWidth+40
+}
+
+func (e *Crawler) Collision(x, y float64) bool {
+	if e.IsInactive() {
+		return false
+	}
+	if e.sprite.CollidePoint(x, y) {
+		e.health--
+		return true
+	}
+	return false
+}
+
+func (e *Crawler) Update() {
+	if e.IsInactive() {
+		return
+	}
+	e.sprite.Move(e.dx, 0)
+
+	cellX, cellY := PosToCell(e.sprite.X(lib.XCentre), e.sprite.Y(lib.YCentre))
+	if cellX >= 0 && cellX < NumGridCols {
+		if rock := e.game.grid[cellY][cellX]; rock != nil {
+			rock.Poison()
+		}
+	}
+	e.sprite.Update()
+}
+
+func (e *Crawler) Draw(screen *ebiten.Image) {
+	if e.IsInactive() {
+		return
+	}
+	e.sprite.Draw(screen)
+}
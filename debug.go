@@ -15,7 +15,7 @@ var (
 )
 
 func (g *Game) displayDebug(screen *ebiten.Image) {
-	template := "\n\n\n TPS: %0.2f - time: %d \n Rocks: %d - Segments: %d - Bullets: %d - Explosions: %d - Occupation: %d\n%s\n%s"
+	template := "\n\n\n TPS: %0.2f - time: %d \n Rocks: %d - Segments: %d - Bullets: %d - Explosions: %d - Occupation: %d\n%s\n Enemies: %v"
 	msg := fmt.Sprintf(template,
 		ebiten.ActualTPS(),
 		g.time,
@@ -24,23 +24,39 @@ func (g *Game) displayDebug(screen *ebiten.Image) {
 		len(g.bullets),
 		len(g.explosions),
 		len(g.occupation),
-		g.player,
-		g.enemy,
+		g.players,
+		g.enemies,
 	)
 	ebitenutil.DebugPrint(screen, msg)
 }
 
 // String returns a debug string
 func (p *Player) String() string {
-	return fmt.Sprintf(" Player lives: %d \n Player coordinates: %s",
-		p.lives,
+	return fmt.Sprintf(" Player %d lives: %d - score: %d \n Player coordinates: %s\n",
+		p.index+1,
+		p.game.Lives(p),
+		p.score,
 		p.sprite.String(),
 	)
 }
 
 // String returns a debug string
 func (e *FlyingEnemy) String() string {
-	return fmt.Sprintf(" Enemy coordinates: %s",
+	return fmt.Sprintf(" Meanie coordinates: %s",
+		e.sprite.String(),
+	)
+}
+
+// String returns a debug string
+func (e *Dropper) String() string {
+	return fmt.Sprintf(" Dropper coordinates: %s",
+		e.sprite.String(),
+	)
+}
+
+// String returns a debug string
+func (e *Crawler) String() string {
+	return fmt.Sprintf(" Crawler coordinates: %s",
 		e.sprite.String(),
 	)
 }
@@ -2,25 +2,71 @@ package main
 
 // Game defaults
 const (
-	WindowWidth         = 480.0
-	WindowHeight        = 800.0
-	WindowTitle         = "Myriapod"
-	SampleRate          = 44100
-	GameNormalSpeed     = 60
-	GameSlowSpeed       = 20
-	NumGridRows         = 25
-	NumGridCols         = 14
-	PlayerMinX          = 40
-	PlayerMaxX          = 440
-	PlayerMinY          = 592
-	PlayerMaxY          = 784
-	PlayerSpawnX        = 240
-	PlayerSpawnY        = 768
-	PlayerWidth         = 40
-	PlayerHeight        = 60
-	InvulnerabilityTime = 100
-	RespawnTime         = 100
-	ReloadTime          = 10
-	InitialRockCount    = 30
-	StartSegments       = 8
+	WindowWidth             = 480.0
+	WindowHeight            = 800.0
+	WindowTitle             = "Myriapod"
+	SampleRate              = 44100
+	GameNormalSpeed         = 60
+	GameSlowSpeed           = 20
+	NumGridRows             = 25
+	NumGridCols             = 14
+	PlayerMinX              = 40
+	PlayerMaxX              = 440
+	PlayerMinY              = 592
+	PlayerMaxY              = 784
+	PlayerSpawnX            = 240
+	PlayerSpawnY            = 768
+	PlayerSpawnSpacing      = 160
+	PlayerLives             = 3
+	PlayerWidth             = 40
+	PlayerHeight            = 60
+	InvulnerabilityTime     = 100
+	RespawnTime             = 100
+	PlayerSpawnEffectTime   = 20
+	ReloadTime              = 10
+	InitialRockCount        = 30
+	StartSegments           = 8
+	AtlasSize               = 2048
+	PopupTime               = 40
+	PopupRise               = 32
+	PopupTextSize           = 18.0
+	ComboTime               = 30
+	MaxLifeIcons            = 4
+	BannerTime              = 60
+	BannerSlideTime         = 30
+	ExtraLifeEvery          = 10000
+	MaxLives                = 9
+	LivesFlashTime          = 60
+	DropperMinWave          = 1
+	DropperRockThreshold    = 5
+	CrawlerMinWave          = 2
+	CrawlerMinRow           = 2
+	CrawlerMaxRow           = 15
+	PoisonDiveRow           = 18
+	PoisonTrailTime         = 16 * 24
+	PowerUpDuration         = 600
+	PowerUpFallSpeed        = 2
+	RapidFireReloadTime     = 4
+	SpreadShotAngle         = 4
+	ShieldBlinkTime         = 120
+	PowerUpBarWidth         = 60
+	PiercingPowerUpCount    = 3
+	ArmourArc               = 1
+	BossScale               = 1.25
+	BossBarWidth            = 240
+	GamepadDeadZone         = 0.3
+	NextPlayerTime          = 180
+	NextPlayerMinTime       = 30
+	VersusVersion           = 1
+	VersusAddr              = ":7777"
+	VersusWaitTime          = 60 // seconds
+	VersusStateInterval     = 30
+	VersusKillsPerAttack    = 4
+	VersusRocksPerAttack    = 3
+	VersusSegmentsPerAttack = 4
+	NetplayAddr             = ":7778"
+	NetplayInputDelay       = 2 // ticks: hides that much latency without rolling back
+	SpectateVersion         = 1
+	LeaderboardAddr         = ":8080"
+	LeaderboardDir          = "leaderboard"
 )
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// dropperTint makes the dropper orange
+var dropperTint = color.RGBA{0xff, 0xa0, 0x40, 0xff}
+
+// Dropper falls straight down from the top of the screen, leaving rocks behind it.
+// It comes when there are not enough rocks left in the player zone.
+type Dropper struct {
+	game      *Game
+	sprite    *lib.Sprite
+	speed     float64
+	health    int
+	lastCellY int
+}
+
+func NewDropper(game *Game) *Dropper {
+	return &Dropper{
+		game:   game,
+		sprite: lib.NewSprite(lib.XCentre, lib.YCentre).SetAnimations(animations).SetTint(dropperTint),
+	}
+}
+
+// CanSpawn returns true when there are only a few rocks left in the player zone
+func (e *Dropper) CanSpawn() bool {
+	if e.game.wave < DropperMinWave {
+		return false
+	}
+	_, minCellY := PosToCell(0, PlayerMinY)
+	rocks := 0
+	for y := minCellY; y < NumGridRows; y++ {
+		for _, rock := range e.game.grid[y] {
+			if rock != nil {
+				rocks++
+			}
+		}
+	}
+	return rocks < DropperRockThreshold && e.game.random.Float64() < .005
+}
+
+// Score returns the number of points for destroying the enemy
+func (e *Dropper) Score() int {
+	return 200
+}
+
+func (e *Dropper) Start() {
+	x, _ := CellToPos(e.game.random.Intn(NumGridCols), 0, 0, 0)
+	e.sprite.MoveTo(x, -32).SetRotation(0).Play("dropper")
+	e.speed = 4
+	e.health = 2
+	e.lastCellY = -1
+}
+
+// Position returns the centre of the enemy
+func (e *Dropper) Position() (float64, float64) {
+	return e.sprite.X(lib.XCentre), e.sprite.Y(lib.YCentre)
+}
+
+// Destroy the enemy at once
+func (e *Dropper) Destroy() {
+	e.health = 0
+}
+
+func (e *Dropper) IsInactive() bool {
+	return e.health <= 0 || e.sprite.Y(lib.YCentre) > WindowHeight+32
+}
+
+func (e *Dropper) Collision(x, y float64) bool {
+	if e.IsInactive() {
+		return false
+	}
+	if e.sprite.CollidePoint(x, y) {
+		e.health--
+		// once hit, it falls twice as fast
+		e.speed = 8
+		return true
+	}
+	return false
+}
+
+func (e *Dropper) Update() {
+	if e.IsInactive() {
+		return
+	}
+	e.sprite.Move(0, e.speed)
+	e.sprite.SetRotation(e.sprite.Rotation() + 0.2)
+
+	// each time we enter a new cell, there's a chance of leaving a rock behind
+	cellX, cellY := PosToCell(e.sprite.X(lib.XCentre), e.sprite.Y(lib.YCentre))
+	if cellY != e.lastCellY {
+		e.lastCellY = cellY
+		if cellY >= 1 && cellY < NumGridRows-2 && e.game.grid[cellY][cellX] == nil && e.game.random.Float64() < .25 && e.game.AllowRock(cellX, cellY) {
+			e.game.grid[cellY][cellX] = NewRock(e.game, cellX, cellY, false)
+			e.game.SoundEffect("rock_create0")
+		}
+	}
+	e.sprite.Update()
+}
+
+func (e *Dropper) Draw(screen *ebiten.Image) {
+	if e.IsInactive() {
+		return
+	}
+	e.sprite.Draw(screen)
+}
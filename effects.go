@@ -0,0 +1,140 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Effects is the camera layer of the game: screen shake, hit-stop (the game freezes for a few frames)
+// and full-screen flash. All of them can be disabled for accessibility.
+type Effects struct {
+	enabled       bool
+	muted         bool // temporarily ignore new effects
+	shakeTime     int
+	shakeDuration int
+	shakeStrength float64
+	hitStop       int
+	flashTime     int
+	flashDuration int
+	flashColour   color.RGBA
+	random        *rand.Rand
+	canvas        *ebiten.Image
+	pixel         *ebiten.Image
+	op            *ebiten.DrawImageOptions
+}
+
+func NewEffects() *Effects {
+	return &Effects{
+		enabled: true,
+		// effects are only visual: they use their own random source so they don't change the game sequence
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		op:     &ebiten.DrawImageOptions{},
+	}
+}
+
+// SetEnabled turns all the effects on or off
+func (e *Effects) SetEnabled(enabled bool) {
+	e.enabled = enabled
+	if !enabled {
+		e.Reset()
+	}
+}
+
+// SetMuted ignores new effects without stopping the running ones
+func (e *Effects) SetMuted(muted bool) {
+	e.muted = muted
+}
+
+// Enabled returns true when the effects are turned on
+func (e *Effects) Enabled() bool {
+	return e.enabled
+}
+
+// Reset stops all running effects
+func (e *Effects) Reset() {
+	e.shakeTime = 0
+	e.hitStop = 0
+	e.flashTime = 0
+}
+
+// Shake the screen for duration frames. The strength (in pixels) decreases over time.
+func (e *Effects) Shake(duration int, strength float64) {
+	if !e.enabled || e.muted || (e.shakeTime > 0 && strength < e.shakeStrength) {
+		return
+	}
+	e.shakeTime = duration
+	e.shakeDuration = duration
+	e.shakeStrength = strength
+}
+
+// HitStop freezes the game for duration frames
+func (e *Effects) HitStop(duration int) {
+	if !e.enabled || e.muted {
+		return
+	}
+	e.hitStop = max(e.hitStop, duration)
+}
+
+// Flash the whole screen with a colour fading out over duration frames. The colour is premultiplied by its alpha.
+func (e *Effects) Flash(colour color.RGBA, duration int) {
+	if !e.enabled || e.muted {
+		return
+	}
+	e.flashColour = colour
+	e.flashTime = duration
+	e.flashDuration = duration
+}
+
+// Update the effects. It returns true if the game should be frozen for this frame.
+func (e *Effects) Update() bool {
+	if e.shakeTime > 0 {
+		e.shakeTime--
+	}
+	if e.flashTime > 0 {
+		e.flashTime--
+	}
+	if e.hitStop > 0 {
+		e.hitStop--
+		return true
+	}
+	return false
+}
+
+// Begin returns the image the game should be drawn onto
+func (e *Effects) Begin(screen *ebiten.Image) *ebiten.Image {
+	if e.shakeTime == 0 {
+		return screen
+	}
+	if e.canvas == nil {
+		e.canvas = ebiten.NewImage(WindowWidth, WindowHeight)
+	}
+	e.canvas.Clear()
+	return e.canvas
+}
+
+// End draws the shaken canvas and the flash onto the screen
+func (e *Effects) End(screen *ebiten.Image) {
+	if e.shakeTime > 0 && e.canvas != nil {
+		strength := e.shakeStrength * float64(e.shakeTime) / float64(e.shakeDuration)
+		e.op.GeoM.Reset()
+		e.op.GeoM.Translate((e.random.Float64()*2-1)*strength, (e.random.Float64()*2-1)*strength)
+		e.op.ColorScale.Reset()
+		screen.DrawImage(e.canvas, e.op)
+	}
+	if e.flashTime > 0 {
+		if e.pixel == nil {
+			e.pixel = ebiten.NewImage(1, 1)
+			e.pixel.Fill(color.White)
+		}
+		alpha := float32(e.flashTime) / float32(e.flashDuration)
+		e.op.GeoM.Reset()
+		e.op.GeoM.Scale(WindowWidth, WindowHeight)
+		e.op.ColorScale.Reset()
+		e.op.ColorScale.ScaleWithColor(e.flashColour)
+		e.op.ColorScale.ScaleAlpha(alpha)
+		screen.DrawImage(e.pixel, e.op)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHitStop(t *testing.T) {
+	effects := NewEffects()
+	effects.HitStop(2)
+	effects.HitStop(1) // shorter hit-stop doesn't cut the current one
+	assert.True(t, effects.Update())
+	assert.True(t, effects.Update())
+	assert.False(t, effects.Update())
+}
+
+func TestDisabledEffects(t *testing.T) {
+	effects := NewEffects()
+	effects.Shake(10, 5)
+	effects.SetEnabled(false)
+	assert.Zero(t, effects.shakeTime)
+
+	effects.Shake(10, 5)
+	effects.HitStop(10)
+	effects.Flash(color.RGBA{0xff, 0xff, 0xff, 0xff}, 10)
+	assert.False(t, effects.Update())
+	assert.Zero(t, effects.shakeTime)
+	assert.Zero(t, effects.flashTime)
+}
+
+func TestShakeKeepsStrongest(t *testing.T) {
+	effects := NewEffects()
+	effects.Shake(10, 8)
+	effects.Shake(20, 2)
+	assert.Equal(t, 10, effects.shakeTime)
+	assert.Equal(t, 8.0, effects.shakeStrength)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Enemy is any enemy other than the myriapod. Each kind of enemy decides when it enters the game.
+type Enemy interface {
+	// CanSpawn is called every frame while the enemy is inactive: it returns true when the enemy should start
+	CanSpawn() bool
+	// Start (or restart) the enemy
+	Start()
+	Update()
+	Draw(screen *ebiten.Image)
+	// IsInactive returns true when the enemy is not in the game
+	IsInactive() bool
+	// Collision returns true when the coordinates hit the enemy, which then loses health
+	Collision(x, y float64) bool
+	// Score returns the number of points for destroying the enemy
+	Score() int
+	// Position returns the centre of the enemy
+	Position() (float64, float64)
+	// Destroy the enemy at once
+	Destroy()
+}
+
+// newEnemies creates one of each kind of enemy
+func (g *Game) newEnemies() []Enemy {
+	return []Enemy{
+		NewFlyingEnemy(g),
+		NewDropper(g),
+		NewCrawler(g),
+	}
+}
+
+func (g *Game) updateEnemies() {
+	for _, enemy := range g.enemies {
+		if enemy.IsInactive() {
+			if enemy.CanSpawn() {
+				enemy.Start()
+			}
+			continue
+		}
+		enemy.Update()
+	}
+}
+
+func (g *Game) drawEnemies(screen *ebiten.Image) {
+	for _, enemy := range g.enemies {
+		enemy.Draw(screen)
+	}
+}
+
+// EnemyCollision returns the first active enemy hit at these coordinates, or nil
+func (g *Game) EnemyCollision(x, y float64) Enemy {
+	for _, enemy := range g.enemies {
+		if enemy.Collision(x, y) {
+			return enemy
+		}
+	}
+	return nil
+}
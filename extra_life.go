@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+)
+
+// ExtraLifeRule decides at which scores the player earns an extra life
+type ExtraLifeRule struct {
+	Scores   []int // fixed thresholds
+	Every    int   // after the last fixed threshold, an extra life every n points (0 for none)
+	MaxLives int   // no extra life is given above this number of lives
+}
+
+// DefaultExtraLifeRule gives an extra life every 10,000 points
+func DefaultExtraLifeRule() ExtraLifeRule {
+	return ExtraLifeRule{
+		Every:    ExtraLifeEvery,
+		MaxLives: MaxLives,
+	}
+}
+
+// ParseExtraLifeScores reads a comma separated list of scores
+func ParseExtraLifeScores(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	items := strings.Split(list, ",")
+	scores := make([]int, len(items))
+	for i, item := range items {
+		score, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || score <= 0 {
+			return nil, fmt.Errorf("invalid extra life score %q", item)
+		}
+		scores[i] = score
+	}
+	sort.Ints(scores)
+	return scores, nil
+}
+
+// Next returns the first threshold strictly above score, or 0 if there's none
+func (r ExtraLifeRule) Next(score int) int {
+	last := 0
+	for _, threshold := range r.Scores {
+		if threshold > score {
+			return threshold
+		}
+		last = threshold
+	}
+	if r.Every <= 0 {
+		return 0
+	}
+	// next multiple of Every after the last fixed threshold
+	return last + ((score-last)/r.Every+1)*r.Every
+}
+
+// checkExtraLife gives an extra life for each threshold the player's score went past
+func (g *Game) checkExtraLife(player *Player) {
+	for player.nextExtraLife > 0 && player.score >= player.nextExtraLife {
+		player.nextExtraLife = g.extraLifeRule.Next(player.nextExtraLife)
+		if !g.AddLife(player) {
+			continue
+		}
+		g.SoundEffect("level_clear")
+		g.hud.FlashLives(player)
+		x, y := player.sprite.X(lib.XCentre), player.sprite.Y(lib.YTop)
+		g.findAvailablePopup().StartText(x, y, "EXTRA LIFE")
+	}
+}
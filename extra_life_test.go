@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraLifeNext(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rule  ExtraLifeRule
+		score int
+		next  int
+	}{
+		{"every from zero", ExtraLifeRule{Every: 10000}, 0, 10000},
+		{"every just below", ExtraLifeRule{Every: 10000}, 9999, 10000},
+		{"every on threshold", ExtraLifeRule{Every: 10000}, 10000, 20000},
+		{"every far", ExtraLifeRule{Every: 10000}, 45678, 50000},
+		{"list", ExtraLifeRule{Scores: []int{5000, 20000}}, 0, 5000},
+		{"list second", ExtraLifeRule{Scores: []int{5000, 20000}}, 5000, 20000},
+		{"list finished", ExtraLifeRule{Scores: []int{5000, 20000}}, 20000, 0},
+		{"list then every", ExtraLifeRule{Scores: []int{5000, 20000}, Every: 30000}, 20000, 50000},
+		{"list then every far", ExtraLifeRule{Scores: []int{5000, 20000}, Every: 30000}, 85000, 110000},
+		{"none", ExtraLifeRule{}, 0, 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.next, testCase.rule.Next(testCase.score))
+		})
+	}
+}
+
+func TestParseExtraLifeScores(t *testing.T) {
+	scores, err := ParseExtraLifeScores("20000, 5000,80000")
+	require.NoError(t, err)
+	assert.Equal(t, []int{5000, 20000, 80000}, scores)
+
+	scores, err = ParseExtraLifeScores("")
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+
+	_, err = ParseExtraLifeScores("1000,abc")
+	assert.Error(t, err)
+	_, err = ParseExtraLifeScores("-1")
+	assert.Error(t, err)
+}
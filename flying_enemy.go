@@ -3,14 +3,16 @@ package main
 import (
 	"math"
 	"math/rand"
+	"strconv"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// FlyingEnemy is the "meanie": it zigzags through the player zone
 type FlyingEnemy struct {
+	game    *Game
 	sprite  *lib.Sprite
-	images  [][]*ebiten.Image
 	movingX float64
 	dx      float64
 	dy      float64
@@ -19,21 +21,32 @@ type FlyingEnemy struct {
 	timer   int
 }
 
-func NewFlyingEnemy() *FlyingEnemy {
+func NewFlyingEnemy(game *Game) *FlyingEnemy {
 	return &FlyingEnemy{
-		sprite: lib.NewSprite(lib.XCentre, lib.YCentre),
-		images: [][]*ebiten.Image{
-			{images["meanie00"], images["meanie01"], images["meanie02"]},
-			{images["meanie10"], images["meanie11"], images["meanie12"]},
-			{images["meanie20"], images["meanie21"], images["meanie22"]},
-		},
+		game:    game,
+		sprite:  lib.NewSprite(lib.XCentre, lib.YCentre).SetAnimations(animations),
 		movingX: 1,
 		health:  1,
 		timer:   0,
 	}
 }
 
-func (e *FlyingEnemy) Start(playerX float64) {
+// CanSpawn gives a 1% chance every frame
+func (e *FlyingEnemy) CanSpawn() bool {
+	return e.game.random.Float64() < .01
+}
+
+// Score returns the number of points for destroying the enemy
+func (e *FlyingEnemy) Score() int {
+	return 20
+}
+
+func (e *FlyingEnemy) Start() {
+	// pick a target among the living players
+	playerX := float64(PlayerSpawnX)
+	if living := e.game.LivingPlayers(); len(living) > 0 {
+		playerX = living[e.game.random.Intn(len(living))].sprite.X(lib.XCentre)
+	}
 	// Choose which side of the screen we start from.
 	// Don't start right next to the player as that would be unfair
 	// if not near player, start on a random side
@@ -43,20 +56,30 @@ func (e *FlyingEnemy) Start(playerX float64) {
 	} else if playerX > 320 {
 		side = 0
 	} else {
-		side = math.Round(rand.Float64() * 2)
+		side = math.Round(e.game.random.Float64() * 2)
 	}
 
 	e.sprite.MoveTo(550*side-35, 688)
 
 	// Always moves in the same X direction, but randomly pauses to just fly straight up or down
-	e.movingX = 1                   // 0 if we're currently moving only vertically, 1 if moving along x axis (as well as y axis)
-	e.dx = 1 - 2*side               // Move left or right depending on which side of the screen we're on
-	e.dy = choice([]float64{-1, 1}) // Start moving either up or down
-	e.color = rand.Intn(3)          // 3 different colours
+	e.movingX = 1                                  // 0 if we're currently moving only vertically, 1 if moving along x axis (as well as y axis)
+	e.dx = 1 - 2*side                              // Move left or right depending on which side of the screen we're on
+	e.dy = choice(e.game.random, []float64{-1, 1}) // Start moving either up or down
+	e.color = e.game.random.Intn(3)                // 3 different colours
 
 	e.health = 1
 	e.timer = 0
-	e.sprite.Animate(e.images[e.color], []int{0, 2, 1, 2}, 4, true)
+	e.sprite.Play("meanie" + strconv.Itoa(e.color))
+}
+
+// Position returns the centre of the enemy
+func (e *FlyingEnemy) Position() (float64, float64) {
+	return e.sprite.X(lib.XCentre), e.sprite.Y(lib.YCentre)
+}
+
+// Destroy the enemy at once
+func (e *FlyingEnemy) Destroy() {
+	e.health = 0
 }
 
 func (e *FlyingEnemy) IsInactive() bool {
@@ -89,7 +112,7 @@ func (e *FlyingEnemy) Update() {
 
 	if y < PlayerMinY || y > PlayerMaxY {
 		// Gone too high or low - reverse y direction
-		e.movingX = math.Round(rand.Float64())
+		e.movingX = math.Round(e.game.random.Float64())
 		e.dy = -e.dy
 	}
 
@@ -103,7 +126,7 @@ func (e *FlyingEnemy) Draw(screen *ebiten.Image) {
 	e.sprite.Draw(screen)
 }
 
-func choice(choices []float64) float64 {
-	i := rand.Intn(len(choices))
+func choice(random *rand.Rand, choices []float64) float64 {
+	i := random.Intn(len(choices))
 	return choices[i]
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	// segmentImages is indexed by fast, 2 health, head, direction (0 to 7) and leg frame (0 to 3): see Segment.update
+	segmentImages [2][2][2][8][4]*ebiten.Image
+	// rockImages is indexed by colour, rock type and health (0 to 4)
+	rockImages [3][4][5]*ebiten.Image
+	// poisonImages is indexed by rock type and health (0 to 4)
+	poisonImages [4][5]*ebiten.Image
+)
+
+// buildFrameTables looks up the images of segments and rocks once,
+// so we don't need to build an image name for each of them on every frame
+func buildFrameTables() {
+	for fast := range segmentImages {
+		for health := range segmentImages[fast] {
+			for head := range segmentImages[fast][health] {
+				for direction := range segmentImages[fast][health][head] {
+					for frame := range segmentImages[fast][health][head][direction] {
+						segmentImages[fast][health][head][direction][frame] = images[fmt.Sprintf("seg%d%d%d%d%d", fast, health, head, direction, frame)]
+					}
+				}
+			}
+		}
+	}
+	for colour := range rockImages {
+		for rockType := range rockImages[colour] {
+			for health := range rockImages[colour][rockType] {
+				rockImages[colour][rockType][health] = images[fmt.Sprintf("rock%d%d%d", colour, rockType, health)]
+			}
+		}
+	}
+	for rockType := range poisonImages {
+		for health := range poisonImages[rockType] {
+			poisonImages[rockType][health] = images[fmt.Sprintf("poison%d%d", rockType, health)]
+		}
+	}
+}
+
+// boolIndex converts a flag into an index in the frame tables
+func boolIndex(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
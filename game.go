@@ -1,11 +1,20 @@
 package main
 
 import (
+	"log"
 	"math/rand"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/particle"
+	"github.com/cavern/creativeprojects/myriapod/lib/random"
+	"github.com/cavern/creativeprojects/myriapod/lib/replay"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/cavern/creativeprojects/myriapod/lib/spectate"
+	"github.com/cavern/creativeprojects/myriapod/lib/versus"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -21,22 +30,58 @@ type Drawable interface {
 }
 
 type Game struct {
-	audioContext *audio.Context
-	musicPlayer  *AudioPlayer
-	background   []*ebiten.Image
-	state        GameState
-	space        *lib.Sprite
-	grid         [][]*Rock
-	occupation   []Cell
-	player       *Player
-	enemy        *FlyingEnemy
-	segments     []*Segment
-	bullets      []*Bullet
-	explosions   []*Explosion
-	wave         int
-	time         int
-	score        int
-	slow         bool
+	audioContext  *audio.Context
+	musicPlayer   *AudioPlayer
+	background    []*ebiten.Image
+	state         GameState
+	space         *lib.Sprite
+	grid          [][]*Rock
+	occupation    []Cell
+	poisonTrail   map[Cell]int // cells where a poisoned head started to dive, with the time they expire
+	players       []*Player
+	sharedLives   bool     // both players take their lives from the same pool
+	boards        []*Board // one per player in an alternating game, nil otherwise
+	turn          int      // board in play in an alternating game
+	stateTimer    int
+	ticks         int            // ticks since the start of the game
+	versus        *versus.Client // connection to the opponent in versus mode
+	opponent      versus.Opponent
+	versusKills   int
+	netplay       *Netplay                         // rollback session of an online co-op game
+	netInputs     [rollback.Players]rollback.Input // inputs of the tick being simulated in netplay
+	replaying     bool                             // simulating a tick again after a rollback
+	spectators    *spectate.Server                 // streams the game to the spectators
+	spectator     *Spectator                       // shows a game streamed by another instance
+	seed          int64                            // random seed of the game in play
+	headless      bool                             // no display nor sound: playing a replay again
+	recording     *replay.Replay                   // inputs of the game in play, nil when it can't be replayed
+	leaderboard   string                           // URL of the leaderboard the scores are submitted to
+	playerName    string                           // name on the leaderboard
+	replayFile    string                           // file the replay of the game is saved to
+	submitted     chan string                      // result of the submission of the game to the leaderboard
+	submission    string                           // status of the submission shown on the game over screen
+	lives         int                              // shared lives
+	enemies       []Enemy
+	segments      []*Segment
+	boss          *Boss
+	bullets       []*Bullet
+	powerUps      []*PowerUp
+	weapon        *Weapon // weapon given to the player at the start of the game
+	explosions    []*Explosion
+	particles     *particle.System
+	effects       *Effects
+	popups        []*Popup
+	hud           *HUD
+	combo         int
+	comboTime     int
+	wave          int
+	time          int
+	highScore     int
+	extraLifeRule ExtraLifeRule
+	slow          bool
+	// game logic only uses this random source, so a game can be played again from the same seed and inputs
+	random       *rand.Rand
+	randomSource *random.Source
 }
 
 // NewGame creates a new game instance and prepares a demo AI game
@@ -47,16 +92,18 @@ func NewGame(audioContext *audio.Context) (*Game, error) {
 	}
 
 	g := &Game{
-		audioContext: audioContext,
-		musicPlayer:  m,
-		background:   []*ebiten.Image{images["bg0"], images["bg1"], images["bg2"]},
-		state:        StateMenu,
-		space: lib.NewSprite(lib.XLeft, lib.YTop).MoveTo(0, 420).Animate([]*ebiten.Image{
-			images["space0"], images["space1"], images["space2"], images["space3"], images["space4"],
-			images["space5"], images["space6"], images["space7"], images["space8"], images["space9"],
-			images["space10"], images["space11"], images["space12"], images["space13"],
-		}, nil, 4, true),
-	}
+		audioContext:  audioContext,
+		musicPlayer:   m,
+		background:    imageList("bg%d", 3),
+		state:         StateMenu,
+		space:         lib.NewSprite(lib.XLeft, lib.YTop).MoveTo(0, 420).SetAnimations(animations).Play("space"),
+		particles:     particle.NewSystem(MaxParticles),
+		effects:       NewEffects(),
+		extraLifeRule: DefaultExtraLifeRule(),
+		weapon:        &Cannon,
+	}
+	g.random, g.randomSource = random.New(time.Now().UnixNano())
+	g.hud = NewHUD(g)
 
 	return g.Initialize(), nil
 }
@@ -66,26 +113,158 @@ func (g *Game) Initialize() *Game {
 	g.state = StateMenu
 	g.wave = -1
 	g.time = 0
-	g.score = 0
+	if g.versus != nil {
+		// a versus game is over once back to the menu
+		g.versus.Close()
+		g.versus = nil
+	}
+	if g.netplay != nil {
+		g.netplay.Close()
+		g.netplay = nil
+	}
+	g.players = nil
+	g.boards = nil
+	g.turn = 0
 	g.segments = make([]*Segment, 0, 20)
+	g.boss = nil
 	g.bullets = make([]*Bullet, 0, 10)
+	g.powerUps = make([]*PowerUp, 0, 4)
 	g.explosions = make([]*Explosion, 0, 10)
+	g.particles.Clear()
+	g.popups = make([]*Popup, 0, 10)
+	g.combo = 0
+	g.hud.Reset()
+	g.effects.Reset()
 	// g.occupation = make([]Cell, StartSegments)
 	g.space.Start()
 	return g
 }
 
-func (g *Game) Start() {
-	rand.Seed(time.Now().UnixNano())
+// Start a game with one or two players. With alternate, two players take turns on their own board.
+func (g *Game) Start(players int, alternate bool) {
+	seed := time.Now().UnixNano()
+	if g.versus != nil {
+		// both boards of a versus game start the same
+		seed = g.versus.Seed()
+	}
+	if g.netplay != nil {
+		// and both sides of a netplay game are the same game
+		seed = g.netplay.seed
+	}
+	g.start(players, alternate, seed)
+	if g.versus == nil && g.netplay == nil && !Debug {
+		// only a local game can be played again: versus attacks come from the network, and debug mode changes the game
+		g.recording = g.newRecording(players, alternate)
+	}
+}
+
+// start a game from a seed
+func (g *Game) start(players int, alternate bool, seed int64) {
+	g.seed = seed
+	g.randomSource.Seed(seed)
+	g.recording = nil
+	// a new channel: the result of the previous game's submission may still come
+	g.submitted = make(chan string, 1)
+	g.submission = ""
+	g.ticks = 0
+	g.versusKills = 0
+	g.opponent = versus.Opponent{}
 	g.newGrid()
-	g.player = NewPlayer(g)
-	g.enemy = NewFlyingEnemy()
-	g.enemy.Start(g.player.sprite.X(lib.XCentre))
+	controls := playerControls()
+	if g.netplay != nil || g.headless {
+		controls = netControls(g)
+	}
+	if alternate {
+		// players take turns: each one is alone on screen, and can use either controls
+		both := AnyControls{controls[0], controls[1]}
+		g.boards = make([]*Board, players)
+		for i := range g.boards {
+			g.boards[i] = NewBoard(NewPlayer(g, i, 1, both))
+		}
+		g.turn = 0
+		g.loadBoard(g.boards[0])
+	} else {
+		g.players = make([]*Player, players)
+		for i := range g.players {
+			g.players[i] = NewPlayer(g, i, players, controls[i])
+		}
+	}
+	g.lives = PlayerLives * players
+	g.enemies = g.newEnemies()
+	// the flying enemy is there from the start
+	g.enemies[0].Start()
 	g.state = StatePlaying
 }
 
-func (g *Game) AddScore(score int) {
-	g.score += score
+// AddScore gives the points to the player. No one scores when player is nil.
+func (g *Game) AddScore(player *Player, score int) {
+	if player == nil {
+		return
+	}
+	player.score += score
+	if player.score > g.highScore {
+		g.highScore = player.score
+	}
+	g.checkExtraLife(player)
+}
+
+// Lives returns the number of lives left to the player
+func (g *Game) Lives(player *Player) int {
+	if g.sharingLives() {
+		return g.lives
+	}
+	return player.lives
+}
+
+// LoseLife takes one life from the player
+func (g *Game) LoseLife(player *Player) {
+	lives := &player.lives
+	if g.sharingLives() {
+		lives = &g.lives
+	}
+	if *lives > 0 {
+		*lives--
+	}
+}
+
+// AddLife gives the player an extra life, up to the maximum. It returns false when the player already has the maximum.
+func (g *Game) AddLife(player *Player) bool {
+	lives, max := &player.lives, g.extraLifeRule.MaxLives
+	if g.sharingLives() {
+		lives, max = &g.lives, g.extraLifeRule.MaxLives*len(g.players)
+	}
+	if *lives >= max {
+		return false
+	}
+	*lives++
+	return true
+}
+
+// sharingLives returns true when the players in play take their lives from the same pool
+func (g *Game) sharingLives() bool {
+	return g.sharedLives && len(g.players) > 1
+}
+
+// LivingPlayers returns the players currently on screen
+func (g *Game) LivingPlayers() []*Player {
+	living := make([]*Player, 0, len(g.players))
+	for _, player := range g.players {
+		if player.alive {
+			living = append(living, player)
+		}
+	}
+	return living
+}
+
+// IsGameOver returns true once all the players have lost all their lives
+func (g *Game) IsGameOver() bool {
+	players := g.allPlayers()
+	for _, player := range players {
+		if !player.IsOut() {
+			return false
+		}
+	}
+	return len(players) > 0
 }
 
 // Layout defines the size of the game in pixels
@@ -135,9 +314,19 @@ func (g *Game) AllowPlayerMovement2(x, y float64, ax, ay int) bool {
 	return true
 }
 
-// Damage returns whether or not there was a rock at this position
-func (g *Game) Damage(cellX, cellY, amount int, fromBullet bool) bool {
-	if cellY < 0 || cellX < 0 {
+// AllowRock returns true if a rock can be created at this grid cell without trapping any player
+func (g *Game) AllowRock(cellX, cellY int) bool {
+	for _, player := range g.players {
+		if !g.AllowPlayerMovement2(player.sprite.X(lib.XCentre), player.sprite.Y(lib.YCentre), cellX, cellY) {
+			return false
+		}
+	}
+	return true
+}
+
+// Damage the rock at this grid cell. by is the player who shot the rock, or nil.
+func (g *Game) Damage(cellX, cellY, amount int, by *Player) bool {
+	if cellY < 0 || cellX < 0 || cellY >= NumGridRows || cellX >= NumGridCols {
 		return false
 	}
 	// Find the rock at this grid cell
@@ -149,7 +338,7 @@ func (g *Game) Damage(cellX, cellY, amount int, fromBullet bool) bool {
 
 	// rock.damage returns False if the rock has lost all its health
 	// in this case, the grid cell will be set to nil
-	if rock.Damage(amount, fromBullet) {
+	if rock.Damage(amount, by) {
 		g.grid[cellY][cellX] = nil
 	}
 
@@ -164,11 +353,41 @@ func (g *Game) ClearRocksForRespawn(x, y float64) {
 
 	for yi := y0; yi <= y1; yi++ {
 		for xi := x0; xi <= x1; xi++ {
-			g.Damage(xi, yi, 5, false)
+			g.Damage(xi, yi, 5, nil)
 		}
 	}
 }
 
+// IsPoisoned returns true if there's a poisoned rock at this grid cell
+func (g *Game) IsPoisoned(cellX, cellY int) bool {
+	if cellX < 0 || cellX >= NumGridCols || cellY < 0 || cellY >= NumGridRows {
+		return false
+	}
+	rock := g.grid[cellY][cellX]
+	return rock != nil && rock.IsPoisoned()
+}
+
+// AddPoisonTrail marks the cell so the segments following a poisoned head also dive from there
+func (g *Game) AddPoisonTrail(cellX, cellY int) {
+	if g.poisonTrail == nil {
+		g.poisonTrail = make(map[Cell]int)
+	}
+	g.poisonTrail[Cell{X: cellX, Y: cellY}] = g.time + PoisonTrailTime
+}
+
+// IsPoisonTrail returns true if a poisoned head went through this cell recently
+func (g *Game) IsPoisonTrail(cellX, cellY int) bool {
+	expire, found := g.poisonTrail[Cell{X: cellX, Y: cellY}]
+	if !found {
+		return false
+	}
+	if g.time > expire {
+		delete(g.poisonTrail, Cell{X: cellX, Y: cellY})
+		return false
+	}
+	return true
+}
+
 func (g *Game) IsOccupied(x, y int) bool {
 	for _, cell := range g.occupation {
 		if cell.X == x && cell.Y == y {
@@ -187,33 +406,75 @@ func (g *Game) IsCellOccupied(cell Cell) bool {
 	return false
 }
 
+// refreshImages fetches again all the images kept by the game objects, after they've been reloaded
+func (g *Game) refreshImages() {
+	buildFrameTables()
+	err := animations.Refresh(images)
+	if err != nil {
+		log.Print(err)
+	}
+	if reloaded, err := loadFont(); err == nil {
+		textFont = reloaded
+	} else {
+		log.Print(err)
+	}
+	copy(g.background, imageList("bg%d", 3))
+	g.hud.refreshImages()
+	for _, player := range g.players {
+		player.refreshImages()
+	}
+	for _, bullet := range g.bullets {
+		if bullet != nil {
+			bullet.refreshImages()
+		}
+	}
+	for _, powerUp := range g.powerUps {
+		powerUp.refreshImages()
+	}
+}
+
 // Update game events
 func (g *Game) Update() error {
-	g.time++
-	if g.wave%4 == 3 {
-		g.time++
+	g.reloadAssets()
+
+	if g.effects.Update() && g.netplay == nil {
+		// hit-stop: the game is frozen for this frame (but a netplay game can't wait)
+		return nil
 	}
 
-	// At the start of each frame, we reset occupied to be an empty set. As each individual myriapod segment is
-	// updated, it will create entries in the occupied set to indicate that other segments should not attempt to
-	// enter its current grid cell. There are two types of entries that are created in the occupied set. One is a
-	// tuple consisting of a pair of numbers, representing grid cell coordinates. The other is a tuple consisting of
-	// three numbers - the first two being grid cell coordinates, the third representing an edge through which a
-	// segment is trying to enter a cell.
-	// It is only used for myriapod segments - not rocks.
-	g.occupation = make([]Cell, 0, StartSegments*20)
+	if g.state == StateSpectating {
+		g.updateSpectator()
+		return nil
+	}
 
 	if g.state == StateMenu {
 		g.space.Update()
 		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-			g.Start()
+			g.Start(1, false)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.Key2) || inpututil.IsKeyJustPressed(ebiten.KeyNumpad2) {
+			g.Start(2, false)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.Key3) || inpututil.IsKeyJustPressed(ebiten.KeyNumpad3) {
+			g.Start(2, true)
 		}
 		return nil
 	}
 
 	if g.state == StatePlaying {
+		// no pause in versus or netplay mode: the other player's game doesn't stop
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) && g.versus == nil && g.netplay == nil {
+			g.state = StatePaused
+			return nil
+		}
 		if inpututil.IsKeyJustPressed(ebiten.KeyD) {
 			Debug = !Debug
+			// the game can't be played again the same way
+			g.recording = nil
+		}
+		// accessibility: toggle screen shake, hit-stop and flash
+		if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+			g.effects.SetEnabled(!g.effects.Enabled())
 		}
 		// toggle between slow and normal speed mode
 		if inpututil.IsKeyJustPressed(ebiten.KeyS) {
@@ -224,47 +485,40 @@ func (g *Game) Update() error {
 				ebiten.SetTPS(GameNormalSpeed)
 			}
 		}
-		if g.enemy.IsInactive() {
-			if rand.Float64() < .01 {
-				g.enemy.Start(g.player.sprite.X(lib.XCentre))
+		if g.netplay != nil {
+			g.updateNetplay()
+			if g.netplay.lost && inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+				g.Initialize()
 			}
+			return nil
 		}
-		if len(g.segments) == 0 {
-			if g.RockCount() <= InitialRockCount+g.wave {
-				g.newRock()
-			} else {
-				// New wave and enough rocks - create a new myriapod
-				g.SoundEffect("wave0")
-				g.wave++
-				g.time = 0
-				numSegments := StartSegments + g.wave/4*2 // On the first four waves there are 8 segments - then 10, and so on
-				for i := 0; i < numSegments; i++ {
-					cellX, cellY := -1-i, 0
-					// Determines whether segments take one or two hits to kill, based on the wave number.
-					// e.g. on wave 0 all segments take one hit; on wave 1 they alternate between one and two hits
-					health := healthTable[g.wave%4][i%2]
-					fast := g.wave%4 == 3 // Every fourth myriapod moves faster than usual
-					head := i == 0        // The first segment of each myriapod is the head
-					segment := NewSegment(g, cellX, cellY, health, fast, head)
-					g.segments = append(g.segments, segment)
-				}
-			}
+		g.record()
+		g.step()
+		return nil
+	}
+
+	if g.state == StatePaused {
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+			g.state = StatePlaying
 		}
-		g.updateSegments()
-		g.updateBullets()
-		g.updateExplosions()
-		g.updateGrid()
-		g.player.Update()
-		g.enemy.Update()
+		return nil
+	}
 
-		if g.player.lives == 0 && g.player.timer == 100 {
-			g.SoundEffect("gameover")
-			g.state = StateGameOver
+	if g.state == StateNextPlayer {
+		g.stateTimer--
+		if g.stateTimer <= 0 || g.players[0].controls.Fire() && g.stateTimer < NextPlayerTime-NextPlayerMinTime {
+			g.state = StatePlaying
 		}
 		return nil
 	}
 
 	if g.state == StateGameOver {
+		g.updateSubmission()
+		g.updateVersusGameOver()
+		if g.netplay != nil {
+			// keep the peer informed until the game is left
+			g.updateNetplay()
+		}
 		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
 			g.Initialize()
 		}
@@ -273,31 +527,140 @@ func (g *Game) Update() error {
 	return nil
 }
 
+// step simulates one tick of the game in play
+func (g *Game) step() {
+	g.time++
+	// a fast myriapod moves twice as fast: time must stay even so it never misses phase 0 and 4 (see Segment.update)
+	if g.fast() && g.time%2 == 1 {
+		g.time++
+	}
+
+	// At the start of each frame, we reset occupied to be an empty set. As each individual myriapod segment is
+	// updated, it will create entries in the occupied set to indicate that other segments should not attempt to
+	// enter its current grid cell. There are two types of entries that are created in the occupied set. One is a
+	// tuple consisting of a pair of numbers, representing grid cell coordinates. The other is a tuple consisting of
+	// three numbers - the first two being grid cell coordinates, the third representing an edge through which a
+	// segment is trying to enter a cell.
+	// It is only used for myriapod segments - not rocks.
+	g.occupation = make([]Cell, 0, StartSegments*20)
+
+	if len(g.segments) == 0 {
+		if g.RockCount() <= InitialRockCount+g.wave {
+			g.newRock()
+		} else {
+			// New wave and enough rocks - create a new myriapod
+			g.SoundEffect("wave0")
+			g.wave++
+			g.time = 0
+			g.poisonTrail = make(map[Cell]int)
+			g.hud.Announce("WAVE " + strconv.Itoa(g.wave+1))
+			if g.wave > 0 {
+				// clearing a wave sends extra segments to the opponent
+				g.sendAttack(versus.AttackSegments, VersusSegmentsPerAttack)
+			}
+			if boss := bossConfig.ForWave(g.wave); boss != nil {
+				g.StartBoss(boss)
+			} else {
+				g.startMyriapod()
+			}
+		}
+	}
+	g.updateSegments()
+	g.updateBullets()
+	g.updatePowerUps()
+	if !g.replaying {
+		// only visual: a tick simulated again after a rollback doesn't play them twice
+		g.updateExplosions()
+		g.particles.Update()
+		g.updatePopups()
+		g.hud.Update()
+	}
+	g.updateGrid()
+	for _, player := range g.players {
+		player.Update()
+	}
+	g.updateEnemies()
+	// once every segment can have been destroyed this tick (bullets, smart bomb), so the boss is resolved
+	// before a new wave starts
+	g.updateBoss()
+	g.ticks++
+	g.updateVersus()
+
+	if g.IsGameOver() {
+		g.SoundEffect("gameover")
+		g.state = StateGameOver
+		g.finishRecording()
+	} else if g.Alternating() {
+		g.checkTurn()
+	}
+	g.sendSpectators()
+}
+
 // Draw game events
 func (g *Game) Draw(screen *ebiten.Image) {
+	target := g.effects.Begin(screen)
+	g.draw(target)
+	g.effects.End(screen)
+	if g.state == StatePlaying && Debug {
+		g.displayDebug(screen)
+	}
+}
+
+func (g *Game) draw(screen *ebiten.Image) {
 	if g.wave < 0 {
 		screen.DrawImage(g.background[0], nil)
 	} else {
 		screen.DrawImage(g.background[g.wave%3], nil)
 	}
 
+	if g.state == StateSpectating {
+		g.drawSpectator(screen)
+		return
+	}
+
 	if g.state == StateMenu {
 		screen.DrawImage(images["title"], nil)
 		g.space.Draw(screen)
+		textFont.Draw(screen, "PRESS 2 FOR TWO PLAYERS", WindowWidth/2, WindowHeight-60, &font.DrawOptions{Align: font.AlignCentre, Scale: 0.6})
+		textFont.Draw(screen, "PRESS 3 TO TAKE TURNS", WindowWidth/2, WindowHeight-40, &font.DrawOptions{Align: font.AlignCentre, Scale: 0.6})
 		return
 	}
 
 	if g.state == StatePlaying {
 		g.drawObjects(screen)
-		g.enemy.Draw(screen)
-		if Debug {
-			g.displayDebug(screen)
-		}
+		g.drawPowerUps(screen)
+		g.particles.Draw(screen)
+		g.drawPopups(screen)
+		g.drawEnemies(screen)
+		g.hud.Draw(screen)
+		g.drawVersus(screen)
+		g.drawNetplay(screen)
+		return
+	}
+
+	if g.state == StatePaused {
+		g.drawObjects(screen)
+		g.drawPowerUps(screen)
+		g.drawEnemies(screen)
+		g.hud.Draw(screen)
+		textFont.Draw(screen, "PAUSED", WindowWidth/2, WindowHeight/2-40, &font.DrawOptions{Align: font.AlignCentre, Scale: 2})
+		textFont.Draw(screen, "PRESS P TO RESUME", WindowWidth/2, WindowHeight/2+20, &font.DrawOptions{Align: font.AlignCentre})
+		return
+	}
+
+	if g.state == StateNextPlayer {
+		g.drawObjects(screen)
+		g.hud.Draw(screen)
+		textFont.Draw(screen, "PLAYER "+strconv.Itoa(g.turn+1), WindowWidth/2, WindowHeight/2-40, &font.DrawOptions{Align: font.AlignCentre, Scale: 2})
+		textFont.Draw(screen, "GET READY", WindowWidth/2, WindowHeight/2+20, &font.DrawOptions{Align: font.AlignCentre})
 		return
 	}
 
 	if g.state == StateGameOver {
 		screen.DrawImage(images["over"], nil)
+		if g.submission != "" {
+			textFont.Draw(screen, g.submission, WindowWidth/2, WindowHeight-60, &font.DrawOptions{Align: font.AlignCentre, Scale: 0.6})
+		}
 		return
 	}
 }
@@ -327,7 +690,9 @@ func (g *Game) drawObjects(screen *ebiten.Image) {
 			objects = append(objects, explosion)
 		}
 	}
-	objects = append(objects, g.player)
+	for _, player := range g.players {
+		objects = append(objects, player)
+	}
 	sort.Slice(objects, func(i, j int) bool {
 		return objects[i].Y() < objects[j].Y()
 	})
@@ -336,13 +701,14 @@ func (g *Game) drawObjects(screen *ebiten.Image) {
 	}
 }
 
-func (g *Game) Fire(x, y float64) {
+// Fire a bullet from a player's weapon (see Weapon.Fire)
+func (g *Game) Fire(owner *Player, x, y float64, shot Shot) {
 	bullet := g.findAvailableBullet()
 	if bullet == nil {
 		bullet = NewBullet(g)
 		g.bullets = append(g.bullets, bullet)
 	}
-	bullet.Start(x, y)
+	bullet.Start(owner, x, y, shot)
 }
 
 func (g *Game) findAvailableBullet() *Bullet {
@@ -358,6 +724,9 @@ func (g *Game) findAvailableBullet() *Bullet {
 }
 
 func (g *Game) Explosion(x, y float64, expType int) {
+	if g.replaying {
+		return
+	}
 	explosion := g.findAvailableExplosion()
 	if explosion == nil {
 		explosion = NewExplosion()
@@ -379,6 +748,10 @@ func (g *Game) findAvailableExplosion() *Explosion {
 }
 
 func (g *Game) SoundEffect(name string) {
+	if g.replaying || g.audioContext == nil {
+		// nothing to play in a headless game
+		return
+	}
 	PlaySE(g.audioContext, sounds[name])
 }
 
@@ -435,11 +808,34 @@ func (g *Game) updateSegments() {
 	}
 }
 
+// startMyriapod creates the myriapod of a normal wave
+func (g *Game) startMyriapod() {
+	numSegments := StartSegments + g.wave/4*2 // On the first four waves there are 8 segments - then 10, and so on
+	for i := 0; i < numSegments; i++ {
+		cellX, cellY := -1-i, 0
+		// Determines whether segments take one or two hits to kill, based on the wave number.
+		// e.g. on wave 0 all segments take one hit; on wave 1 they alternate between one and two hits
+		health := healthTable[g.wave%4][i%2]
+		fast := g.wave%4 == 3 // Every fourth myriapod moves faster than usual
+		head := i == 0        // The first segment of each myriapod is the head
+		segment := NewSegment(g, cellX, cellY, health, fast, head)
+		g.segments = append(g.segments, segment)
+	}
+}
+
+// fast returns true when the myriapod moves faster than usual
+func (g *Game) fast() bool {
+	if g.boss != nil {
+		return g.boss.Fast()
+	}
+	return g.wave%4 == 3
+}
+
 func (g *Game) newRock() {
 	// retry every time we pick coordinates that already contain a rock
 	for {
-		x := rand.Intn(NumGridCols)
-		y := rand.Intn(NumGridRows-3) + 1 // Leave last 2 rows rock-free
+		x := g.random.Intn(NumGridCols)
+		y := g.random.Intn(NumGridRows-3) + 1 // Leave last 2 rows rock-free
 		if rock := g.grid[y][x]; rock == nil {
 			g.grid[y][x] = NewRock(g, x, y, false)
 			return
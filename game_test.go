@@ -2,11 +2,24 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+var loadTestResources = sync.OnceValue(loadResources)
+
+// newHeadlessTestGame returns a game started with the embedded resources, without display nor sound
+func newHeadlessTestGame(t *testing.T, players int, seed int64) *Game {
+	t.Helper()
+	require.NoError(t, loadTestResources())
+	game := NewHeadlessGame()
+	game.start(players, false, seed)
+	return game
+}
+
 func TestPixelPosition(t *testing.T) {
 	for x := 0; x < NumGridCols; x++ {
 		for y := 0; y < NumGridRows; y++ {
@@ -43,3 +56,57 @@ func BenchmarkCellToPos(b *testing.B) {
 		assert.EqualValues(b, 381, y)
 	}
 }
+
+func TestLives(t *testing.T) {
+	testCases := []struct {
+		name    string
+		shared  bool
+		player1 int
+		player2 int
+	}{
+		{"separate", false, 2, 3},
+		{"shared", true, 5, 5},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			game := &Game{sharedLives: testCase.shared, lives: 2 * PlayerLives, extraLifeRule: DefaultExtraLifeRule()}
+			game.players = []*Player{
+				{game: game, index: 0, lives: PlayerLives, alive: true},
+				{game: game, index: 1, lives: PlayerLives, alive: true},
+			}
+			game.LoseLife(game.players[0])
+			assert.Equal(t, testCase.player1, game.Lives(game.players[0]))
+			assert.Equal(t, testCase.player2, game.Lives(game.players[1]))
+
+			require.True(t, game.AddLife(game.players[1]))
+			assert.Equal(t, testCase.player2+1, game.Lives(game.players[1]))
+		})
+	}
+}
+
+func TestLivesNeverNegative(t *testing.T) {
+	game := &Game{sharedLives: true, lives: 1}
+	player := &Player{game: game}
+	game.LoseLife(player)
+	game.LoseLife(player)
+	assert.Equal(t, 0, game.Lives(player))
+}
+
+func TestGameOverWhenAllPlayersAreOut(t *testing.T) {
+	game := &Game{}
+	assert.False(t, game.IsGameOver(), "no game started")
+
+	game.players = []*Player{
+		{game: game, index: 0, lives: 0, alive: false, timer: RespawnTime},
+		{game: game, index: 1, lives: 1, alive: true},
+	}
+	assert.False(t, game.IsGameOver())
+	assert.Len(t, game.LivingPlayers(), 1)
+	assert.Same(t, game.players[1], game.LivingPlayers()[0])
+
+	game.players[1].lives = 0
+	game.players[1].alive = false
+	game.players[1].timer = RespawnTime
+	assert.True(t, game.IsGameOver())
+	assert.Empty(t, game.LivingPlayers())
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/tween"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// HUD displays the score, high score, wave number and lives on top of the game, and announces each new wave
+type HUD struct {
+	game        *Game
+	life        *ebiten.Image
+	banner      string
+	bannerX     float64
+	bannerTween tween.Tweener
+	livesFlash  [2]int // per player
+	op          *ebiten.DrawImageOptions
+}
+
+func NewHUD(game *Game) *HUD {
+	return &HUD{
+		game: game,
+		life: images["life"],
+		op:   &ebiten.DrawImageOptions{},
+	}
+}
+
+// refreshImages is called after the images have been reloaded
+func (h *HUD) refreshImages() {
+	h.life = images["life"]
+}
+
+// Reset removes any banner still displayed
+func (h *HUD) Reset() {
+	h.bannerTween = nil
+	h.livesFlash = [2]int{}
+}
+
+// FlashLives makes the lives of the player blink for a moment (when an extra life is earned)
+func (h *HUD) FlashLives(player *Player) {
+	h.livesFlash[player.index] = LivesFlashTime
+}
+
+// Announce slides a banner through the middle of the screen
+func (h *HUD) Announce(text string) {
+	if h.game.replaying {
+		return
+	}
+	h.banner = text
+	h.bannerX = -WindowWidth / 2
+	set := func(value float64) {
+		h.bannerX = value
+	}
+	h.bannerTween = tween.NewSequence(
+		tween.New(-WindowWidth/2, WindowWidth/2, BannerSlideTime, tween.OutCubic, set),
+		tween.Wait(BannerTime),
+		tween.New(WindowWidth/2, WindowWidth*3/2, BannerSlideTime, tween.InCubic, set),
+	)
+}
+
+func (h *HUD) Update() {
+	for i := range h.livesFlash {
+		if h.livesFlash[i] > 0 {
+			h.livesFlash[i]--
+		}
+	}
+	if h.bannerTween != nil && h.bannerTween.Update() {
+		h.bannerTween = nil
+	}
+}
+
+func (h *HUD) Draw(screen *ebiten.Image) {
+	players := h.game.players
+	if len(players) == 1 {
+		// one player: lives on the left, score on the right
+		h.drawPlayerLives(screen, players[0], false)
+		h.drawPowerUps(screen, players[0], 8, 44)
+		h.drawScore(screen, players[0].score, 448, 5, true)
+	} else if len(players) == 2 {
+		// two players: one column on each side of the screen
+		h.drawPlayerLives(screen, players[0], false)
+		h.drawScore(screen, players[0].score, 8, 40, false)
+		h.drawPowerUps(screen, players[0], 8, 76)
+		if !h.game.sharedLives {
+			h.drawPlayerLives(screen, players[1], true)
+		}
+		h.drawScore(screen, players[1].score, 448, 40, true)
+		h.drawPowerUps(screen, players[1], WindowWidth-130, 76)
+	}
+	h.drawInfo(screen)
+	if h.game.boss != nil {
+		y := 42.0
+		if len(players) > 1 {
+			y = 96
+		}
+		h.drawBoss(screen, h.game.boss, y)
+	}
+	if h.bannerTween != nil {
+		textFont.Draw(screen, h.banner, h.bannerX, WindowHeight/2-float64(textFont.LineHeight()), &font.DrawOptions{
+			Align: font.AlignCentre,
+			Scale: 2,
+		})
+	}
+}
+
+func (h *HUD) drawPlayerLives(screen *ebiten.Image, player *Player, right bool) {
+	if (h.livesFlash[player.index]/4)%2 == 0 {
+		h.drawLives(screen, h.game.Lives(player), right)
+	}
+}
+
+// drawLives from the left edge of the screen, or from the right edge
+func (h *HUD) drawLives(screen *ebiten.Image, lives int, right bool) {
+	// Display number of lives: one icon each, or one icon and the count when there are too many to fit
+	icons := lives
+	if lives > MaxLifeIcons {
+		icons = 1
+	}
+	for i := 0; i < icons; i++ {
+		x := float64(i)*40 + 8
+		if right {
+			x = WindowWidth - 40 - x
+		}
+		h.op.GeoM.Reset()
+		h.op.GeoM.Translate(x, 4)
+		screen.DrawImage(h.life, h.op)
+	}
+	if lives > MaxLifeIcons {
+		options := &font.DrawOptions{Scale: 0.8}
+		x := 46.0
+		if right {
+			options.Align = font.AlignRight
+			x = WindowWidth - x
+		}
+		textFont.Draw(screen, "X "+strconv.Itoa(lives), x, 8, options)
+	}
+}
+
+// drawPowerUps lists the active power-ups under the lives, each with a bar showing the time left
+func (h *HUD) drawPowerUps(screen *ebiten.Image, player *Player, x, y float64) {
+	for kind := PowerUpType(0); kind < numPowerUps; kind++ {
+		if !player.HasPowerUp(kind) {
+			continue
+		}
+		textFont.Draw(screen, strings.ToUpper(kind.String()), x, y, &font.DrawOptions{
+			Colour: powerUpColours[kind],
+			Scale:  0.5,
+		})
+		width := float32(PowerUpBarWidth * player.PowerUpTime(kind))
+		vector.DrawFilledRect(screen, float32(x)+56, float32(y)+3, width, 6, powerUpColours[kind], false)
+		y += 16
+	}
+}
+
+// drawBoss shows the name of the boss and the health of its head
+func (h *HUD) drawBoss(screen *ebiten.Image, boss *Boss, y float64) {
+	left, top := float32(WindowWidth-BossBarWidth)/2, float32(y)+16
+	textFont.Draw(screen, boss.definition.Name, WindowWidth/2, y, &font.DrawOptions{Align: font.AlignCentre, Scale: 0.5})
+	vector.StrokeRect(screen, left, top, BossBarWidth, 8, 1, color.RGBA{0xff, 0xff, 0xff, 0xc0}, false)
+	vector.DrawFilledRect(screen, left+1, top+1, float32(boss.HeadHealth()*(BossBarWidth-2)), 6, color.RGBA{0xff, 0x40, 0x40, 0xff}, false)
+}
+
+// drawScore with the first digit at x, or the last digit at x when right aligned
+func (h *HUD) drawScore(screen *ebiten.Image, score int, x, y float64, right bool) {
+	digits := strconv.Itoa(score)
+	if right {
+		x -= float64(len(digits)-1) * 24
+	}
+	for i := 0; i < len(digits); i++ {
+		h.op.GeoM.Reset()
+		h.op.GeoM.Translate(x+float64(i)*24, y)
+		screen.DrawImage(images["digit"+string(digits[i])], h.op)
+	}
+}
+
+func (h *HUD) drawInfo(screen *ebiten.Image) {
+	options := &font.DrawOptions{Align: font.AlignCentre, Scale: 0.6}
+	textFont.Draw(screen, "HI "+strconv.Itoa(h.game.highScore), WindowWidth/2, 4, options)
+	if h.game.wave >= 0 {
+		wave := "WAVE " + strconv.Itoa(h.game.wave+1)
+		if h.game.Alternating() {
+			wave = "P" + strconv.Itoa(h.game.turn+1) + " " + wave
+		}
+		textFont.Draw(screen, wave, WindowWidth/2, 22, options)
+	}
+}
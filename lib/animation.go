@@ -0,0 +1,193 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+
+	_ "image/png"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// LoopMode defines what happens when a clip reaches its last frame
+type LoopMode int
+
+// LoopMode
+const (
+	LoopOnce LoopMode = iota
+	LoopForever
+	LoopPingPong
+)
+
+// String representation of LoopMode
+func (l LoopMode) String() string {
+	switch l {
+	case LoopForever:
+		return "loop"
+	case LoopPingPong:
+		return "pingpong"
+	default:
+		return "once"
+	}
+}
+
+// UnmarshalText reads a LoopMode from its string representation
+func (l *LoopMode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "once", "":
+		*l = LoopOnce
+	case "loop":
+		*l = LoopForever
+	case "pingpong":
+		*l = LoopPingPong
+	default:
+		return fmt.Errorf("unknown loop mode %q", string(text))
+	}
+	return nil
+}
+
+// EventFunc is called when an animation reaches a frame with an event attached
+type EventFunc func(event string)
+
+// Clip is a named animation: a list of frames, each with its own duration (in ticks)
+type Clip struct {
+	Name      string
+	Frames    []*ebiten.Image
+	Durations []int
+	Loop      LoopMode
+	Events    map[int]string // event name indexed by frame
+	names     []string
+}
+
+// Length returns the number of frames in the clip
+func (c *Clip) Length() int {
+	return len(c.Frames)
+}
+
+// Duration returns the total number of ticks to play the clip once
+func (c *Clip) Duration() int {
+	total := 0
+	for _, duration := range c.Durations {
+		total += duration
+	}
+	return total
+}
+
+// sheetDefinition is the JSON description of a sprite sheet and its clips
+type sheetDefinition struct {
+	Image  string                    `json:"image"`
+	Frames map[string]rectDefinition `json:"frames"`
+	Clips  map[string]clipDefinition `json:"clips"`
+}
+
+type rectDefinition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type clipDefinition struct {
+	Frames    []string       `json:"frames"`
+	Duration  int            `json:"duration"`  // default duration of each frame
+	Durations []int          `json:"durations"` // per frame duration (overrides duration)
+	Loop      LoopMode       `json:"loop"`
+	Events    map[int]string `json:"events"`
+}
+
+// AnimationSet is a collection of clips loaded from a sprite sheet description
+type AnimationSet struct {
+	frames map[string]*ebiten.Image // frames cut from the sprite sheet image
+	clips  map[string]*Clip
+}
+
+// LoadAnimationSet reads the JSON description file from fsys. Frames are cut from the sprite sheet image
+// (path relative to the description) when the description has one, and are otherwise looked up in images.
+func LoadAnimationSet(fsys fs.FS, name string, images map[string]*ebiten.Image) (*AnimationSet, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	definition := sheetDefinition{}
+	err = json.Unmarshal(data, &definition)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	set := &AnimationSet{
+		frames: make(map[string]*ebiten.Image, len(definition.Frames)),
+		clips:  make(map[string]*Clip, len(definition.Clips)),
+	}
+	if definition.Image != "" {
+		file, err := fsys.Open(path.Join(path.Dir(name), definition.Image))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		defer file.Close()
+		img, _, err := image.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", definition.Image, err)
+		}
+		sheet := ebiten.NewImageFromImage(img)
+		for frameName, rect := range definition.Frames {
+			set.frames[frameName] = sheet.SubImage(image.Rect(rect.X, rect.Y, rect.X+rect.W, rect.Y+rect.H)).(*ebiten.Image)
+		}
+	}
+
+	for clipName, clipDefinition := range definition.Clips {
+		if len(clipDefinition.Frames) == 0 {
+			return nil, fmt.Errorf("%s: clip %q has no frame", name, clipName)
+		}
+		clip := &Clip{
+			Name:      clipName,
+			Frames:    make([]*ebiten.Image, len(clipDefinition.Frames)),
+			Durations: make([]int, len(clipDefinition.Frames)),
+			Loop:      clipDefinition.Loop,
+			Events:    clipDefinition.Events,
+			names:     clipDefinition.Frames,
+		}
+		for i := range clip.Durations {
+			clip.Durations[i] = clipDefinition.Duration
+			if i < len(clipDefinition.Durations) {
+				clip.Durations[i] = clipDefinition.Durations[i]
+			}
+			if clip.Durations[i] < 1 {
+				clip.Durations[i] = 1
+			}
+		}
+		set.clips[clipName] = clip
+	}
+
+	err = set.Refresh(images)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return set, nil
+}
+
+// Refresh looks up again the frames that don't come from the sprite sheet (after the images have been reloaded).
+// The frame slices are updated in place so the sprites playing a clip pick up the new images.
+func (a *AnimationSet) Refresh(images map[string]*ebiten.Image) error {
+	for _, clip := range a.clips {
+		for i, frameName := range clip.names {
+			if frame, found := a.frames[frameName]; found {
+				clip.Frames[i] = frame
+				continue
+			}
+			frame, found := images[frameName]
+			if !found {
+				return fmt.Errorf("clip %q: frame %q not found", clip.Name, frameName)
+			}
+			clip.Frames[i] = frame
+		}
+	}
+	return nil
+}
+
+// Clip returns the clip by its name, or nil if not found
+func (a *AnimationSet) Clip(name string) *Clip {
+	return a.clips[name]
+}
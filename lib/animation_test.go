@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAnimations = `{
+  "clips": {
+    "once": {"frames": ["a", "b", "c"], "duration": 2},
+    "loop": {"frames": ["a", "b"], "durations": [1, 3], "loop": "loop"},
+    "pingpong": {"frames": ["a", "b", "c"], "duration": 1, "loop": "pingpong", "events": {"2": "end"}},
+    "single": {"frames": ["a"], "duration": 1, "loop": "pingpong"}
+  }
+}`
+
+func loadTestAnimations(t *testing.T) *AnimationSet {
+	t.Helper()
+	fsys := fstest.MapFS{"animations.json": &fstest.MapFile{Data: []byte(testAnimations)}}
+	images := map[string]*ebiten.Image{"a": nil, "b": nil, "c": nil}
+	set, err := LoadAnimationSet(fsys, "animations.json", images)
+	require.NoError(t, err)
+	return set
+}
+
+func TestLoadAnimationSet(t *testing.T) {
+	set := loadTestAnimations(t)
+	require.NotNil(t, set.Clip("once"))
+	assert.Equal(t, 3, set.Clip("once").Length())
+	assert.Equal(t, 6, set.Clip("once").Duration())
+	assert.Equal(t, LoopOnce, set.Clip("once").Loop)
+	assert.Equal(t, []int{1, 3}, set.Clip("loop").Durations)
+	assert.Equal(t, LoopPingPong, set.Clip("pingpong").Loop)
+	assert.Equal(t, "end", set.Clip("pingpong").Events[2])
+	assert.Nil(t, set.Clip("unknown"))
+}
+
+func TestLoadAnimationSetMissingFrame(t *testing.T) {
+	fsys := fstest.MapFS{"animations.json": &fstest.MapFile{Data: []byte(testAnimations)}}
+	_, err := LoadAnimationSet(fsys, "animations.json", map[string]*ebiten.Image{"a": nil})
+	assert.Error(t, err)
+}
+
+func TestPlayClip(t *testing.T) {
+	set := loadTestAnimations(t)
+	testCases := []struct {
+		clip     string
+		frames   []int
+		finished bool
+	}{
+		{"once", []int{0, 1, 1, 2, 2, 2, 2}, true},
+		{"loop", []int{1, 1, 1, 0, 1, 1, 1, 0}, false},
+		{"pingpong", []int{1, 2, 1, 0, 1, 2, 1}, false},
+		{"single", []int{0, 0, 0, 0}, false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.clip, func(t *testing.T) {
+			sprite := NewSprite(XCentre, YCentre).SetAnimations(set).Play(testCase.clip)
+			assert.Equal(t, testCase.clip, sprite.ClipName())
+			assert.Equal(t, 0, sprite.clipFrame)
+			frames := make([]int, len(testCase.frames))
+			for i := range frames {
+				sprite.Update()
+				frames[i] = sprite.clipFrame
+			}
+			assert.Equal(t, testCase.frames, frames)
+			assert.Equal(t, testCase.finished, sprite.IsFinished())
+		})
+	}
+}
+
+func TestClipEvents(t *testing.T) {
+	set := loadTestAnimations(t)
+	events := 0
+	sprite := NewSprite(XCentre, YCentre).SetAnimations(set).SetEventFunc(func(event string) {
+		assert.Equal(t, "end", event)
+		events++
+	}).Play("pingpong")
+	for i := 0; i < 8; i++ {
+		sprite.Update()
+	}
+	// frame 2 is reached on the 2nd and 6th updates
+	assert.Equal(t, 2, events)
+}
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AtlasPadding is the number of transparent pixels left around each image, so filtering never bleeds into a neighbour
+const AtlasPadding = 1
+
+// Placement is the position of an image inside an atlas
+type Placement struct {
+	Page int
+	Rect image.Rectangle
+}
+
+// PackLayout places rectangles of the given sizes onto square pages of pageSize pixels, using shelves
+// (images sorted by height, filling rows from left to right). The layout only depends on the names and sizes,
+// so the same set of images always produces the same atlas.
+func PackLayout(sizes map[string]image.Point, pageSize int) (map[string]Placement, int, error) {
+	names := make([]string, 0, len(sizes))
+	for name, size := range sizes {
+		if size.X+2*AtlasPadding > pageSize || size.Y+2*AtlasPadding > pageSize {
+			return nil, 0, fmt.Errorf("%s (%dx%d) doesn't fit in a %dx%d atlas", name, size.X, size.Y, pageSize, pageSize)
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if sizes[names[i]].Y != sizes[names[j]].Y {
+			return sizes[names[i]].Y > sizes[names[j]].Y
+		}
+		return names[i] < names[j]
+	})
+
+	placements := make(map[string]Placement, len(names))
+	page, x, y, shelfHeight := 0, 0, 0, 0
+	for _, name := range names {
+		width := sizes[name].X + 2*AtlasPadding
+		height := sizes[name].Y + 2*AtlasPadding
+		if x+width > pageSize {
+			// next shelf
+			x = 0
+			y += shelfHeight
+			shelfHeight = 0
+		}
+		if y+height > pageSize {
+			// next page
+			page++
+			x, y, shelfHeight = 0, 0, 0
+		}
+		min := image.Pt(x+AtlasPadding, y+AtlasPadding)
+		placements[name] = Placement{
+			Page: page,
+			Rect: image.Rectangle{Min: min, Max: min.Add(sizes[name])},
+		}
+		x += width
+		if height > shelfHeight {
+			shelfHeight = height
+		}
+	}
+	pages := 0
+	if len(names) > 0 {
+		pages = page + 1
+	}
+	return placements, pages, nil
+}
+
+// Atlas holds a few large textures containing many small images
+type Atlas struct {
+	pages  []*ebiten.Image
+	images map[string]*ebiten.Image
+}
+
+// NewAtlas packs all the source images onto pages of pageSize x pageSize pixels
+func NewAtlas(sources map[string]image.Image, pageSize int) (*Atlas, error) {
+	sizes := make(map[string]image.Point, len(sources))
+	for name, source := range sources {
+		sizes[name] = source.Bounds().Size()
+	}
+	placements, pageCount, err := PackLayout(sizes, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// compose the pages in memory so each page is only uploaded once
+	canvases := make([]*image.RGBA, pageCount)
+	for i := range canvases {
+		canvases[i] = image.NewRGBA(image.Rect(0, 0, pageSize, pageSize))
+	}
+	for name, placement := range placements {
+		source := sources[name]
+		draw.Draw(canvases[placement.Page], placement.Rect, source, source.Bounds().Min, draw.Src)
+	}
+
+	atlas := &Atlas{
+		pages:  make([]*ebiten.Image, pageCount),
+		images: make(map[string]*ebiten.Image, len(placements)),
+	}
+	for i, canvas := range canvases {
+		atlas.pages[i] = ebiten.NewImageFromImage(canvas)
+	}
+	for name, placement := range placements {
+		atlas.images[name] = atlas.pages[placement.Page].SubImage(placement.Rect).(*ebiten.Image)
+	}
+	return atlas, nil
+}
+
+// Pages returns the textures of the atlas
+func (a *Atlas) Pages() []*ebiten.Image {
+	return a.pages
+}
+
+// Images returns a sub-image of the atlas for each source image
+func (a *Atlas) Images() map[string]*ebiten.Image {
+	return a.images
+}
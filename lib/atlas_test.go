@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackLayout(t *testing.T) {
+	sizes := make(map[string]image.Point)
+	for i := 0; i < 50; i++ {
+		sizes[fmt.Sprintf("image%d", i)] = image.Pt(10+i%7*9, 12+i%5*11)
+	}
+	placements, pages, err := PackLayout(sizes, 128)
+	require.NoError(t, err)
+	assert.Greater(t, pages, 1)
+	assert.Len(t, placements, len(sizes))
+
+	for name, placement := range placements {
+		assert.Equal(t, sizes[name], placement.Rect.Size())
+		assert.True(t, placement.Rect.In(image.Rect(0, 0, 128, 128)), name)
+		padded := placement.Rect.Inset(-AtlasPadding)
+		for otherName, other := range placements {
+			if otherName == name || other.Page != placement.Page {
+				continue
+			}
+			assert.False(t, padded.Overlaps(other.Rect), "%s overlaps %s", name, otherName)
+		}
+	}
+}
+
+func TestPackLayoutTooBig(t *testing.T) {
+	_, _, err := PackLayout(map[string]image.Point{"big": image.Pt(128, 10)}, 128)
+	assert.Error(t, err)
+}
+
+func TestPackLayoutEmpty(t *testing.T) {
+	placements, pages, err := PackLayout(map[string]image.Point{}, 128)
+	require.NoError(t, err)
+	assert.Empty(t, placements)
+	assert.Equal(t, 0, pages)
+}
@@ -0,0 +1,222 @@
+// Package font draws text using a bitmap font: a sheet of glyph images described by a JSON file.
+package font
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Align is the horizontal alignment of the text, relative to the x coordinate
+type Align int
+
+// Align
+const (
+	AlignLeft Align = iota
+	AlignCentre
+	AlignRight
+)
+
+// Glyph is the image of one character
+type Glyph struct {
+	Image   *ebiten.Image
+	Advance int // distance to the next character
+}
+
+// DrawOptions changes how the text is drawn. A nil *DrawOptions draws white text aligned on the left.
+type DrawOptions struct {
+	Align    Align
+	Colour   color.Color // multiplied with the glyph images (nil for none)
+	Alpha    float64     // opacity: 0 is treated as fully opaque
+	Scale    float64     // 0 is treated as 1
+	MaxWidth float64     // wrap the lines longer than MaxWidth (in scaled pixels). 0 for no wrapping
+}
+
+// Font is a bitmap font
+type Font struct {
+	glyphs     map[rune]*Glyph
+	kerning    map[[2]rune]int
+	lineHeight int
+	op         *ebiten.DrawImageOptions
+}
+
+type definition struct {
+	Image      string                     `json:"image"`
+	LineHeight int                        `json:"lineHeight"`
+	Glyphs     map[string]glyphDefinition `json:"glyphs"`
+	Kerning    map[string]int             `json:"kerning"`
+}
+
+type glyphDefinition struct {
+	X       int `json:"x"`
+	Y       int `json:"y"`
+	W       int `json:"w"`
+	H       int `json:"h"`
+	Advance int `json:"advance"`
+}
+
+// Load a font from its JSON description. The glyph sheet is looked up by name in images.
+func Load(data []byte, images map[string]*ebiten.Image) (*Font, error) {
+	def := definition{}
+	err := json.Unmarshal(data, &def)
+	if err != nil {
+		return nil, err
+	}
+	sheet, found := images[def.Image]
+	if !found || sheet == nil {
+		return nil, fmt.Errorf("font image %q not found", def.Image)
+	}
+	font := &Font{
+		glyphs:     make(map[rune]*Glyph, len(def.Glyphs)),
+		kerning:    make(map[[2]rune]int, len(def.Kerning)),
+		lineHeight: def.LineHeight,
+		op:         &ebiten.DrawImageOptions{},
+	}
+	// the sheet can be a sub-image (from an atlas): glyph positions are relative to its top left corner
+	origin := sheet.Bounds().Min
+	for character, glyph := range def.Glyphs {
+		r, size := utf8.DecodeRuneInString(character)
+		if size != len(character) {
+			return nil, fmt.Errorf("glyph %q should be a single character", character)
+		}
+		rect := image.Rect(glyph.X, glyph.Y, glyph.X+glyph.W, glyph.Y+glyph.H).Add(origin)
+		font.glyphs[r] = &Glyph{
+			Image:   sheet.SubImage(rect).(*ebiten.Image),
+			Advance: glyph.Advance,
+		}
+	}
+	for pair, amount := range def.Kerning {
+		runes := []rune(pair)
+		if len(runes) != 2 {
+			return nil, fmt.Errorf("kerning pair %q should be two characters", pair)
+		}
+		font.kerning[[2]rune{runes[0], runes[1]}] = amount
+	}
+	return font, nil
+}
+
+// LineHeight returns the height of a line of text (unscaled)
+func (f *Font) LineHeight() int {
+	return f.lineHeight
+}
+
+// glyph returns the glyph for this character, trying the upper case version if not found
+func (f *Font) glyph(r rune) *Glyph {
+	if glyph, found := f.glyphs[r]; found {
+		return glyph
+	}
+	return f.glyphs[unicode.ToUpper(r)]
+}
+
+func (f *Font) kern(previous, current rune) int {
+	if previous == 0 {
+		return 0
+	}
+	return f.kerning[[2]rune{unicode.ToUpper(previous), unicode.ToUpper(current)}]
+}
+
+// Width returns the width of a single line of text (unscaled). Unknown characters are skipped.
+func (f *Font) Width(line string) int {
+	width := 0
+	var previous rune
+	for _, r := range line {
+		glyph := f.glyph(r)
+		if glyph == nil {
+			continue
+		}
+		width += f.kern(previous, r) + glyph.Advance
+		previous = r
+	}
+	return width
+}
+
+// Measure returns the size of the text (unscaled), after wrapping the lines longer than maxWidth (0 for no wrapping)
+func (f *Font) Measure(text string, maxWidth int) (int, int) {
+	lines := f.Wrap(text, maxWidth)
+	width := 0
+	for _, line := range lines {
+		width = max(width, f.Width(line))
+	}
+	return width, len(lines) * f.lineHeight
+}
+
+// Wrap splits the text into lines no longer than maxWidth (unscaled), breaking on spaces.
+// Explicit new lines are kept. A word longer than maxWidth stays on its own line.
+func (f *Font) Wrap(text string, maxWidth int) []string {
+	paragraphs := strings.Split(text, "\n")
+	if maxWidth <= 0 {
+		return paragraphs
+	}
+	lines := make([]string, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line == "" {
+				line = word
+				continue
+			}
+			if f.Width(line+" "+word) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Draw the text with its top at y. Depending on the alignment, x is the left, centre or right of each line.
+func (f *Font) Draw(screen *ebiten.Image, text string, x, y float64, options *DrawOptions) {
+	if options == nil {
+		options = &DrawOptions{}
+	}
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	lines := f.Wrap(text, int(options.MaxWidth/scale))
+	for _, line := range lines {
+		left := x
+		switch options.Align {
+		case AlignCentre:
+			left -= float64(f.Width(line)) * scale / 2
+		case AlignRight:
+			left -= float64(f.Width(line)) * scale
+		}
+		f.drawLine(screen, line, left, y, scale, options)
+		y += float64(f.lineHeight) * scale
+	}
+}
+
+func (f *Font) drawLine(screen *ebiten.Image, line string, x, y, scale float64, options *DrawOptions) {
+	position := 0
+	var previous rune
+	for _, r := range line {
+		glyph := f.glyph(r)
+		if glyph == nil {
+			continue
+		}
+		position += f.kern(previous, r)
+		previous = r
+		f.op.GeoM.Reset()
+		f.op.GeoM.Scale(scale, scale)
+		f.op.GeoM.Translate(x+float64(position)*scale, y)
+		f.op.ColorScale.Reset()
+		if options.Colour != nil {
+			f.op.ColorScale.ScaleWithColor(options.Colour)
+		}
+		if options.Alpha > 0 && options.Alpha < 1 {
+			f.op.ColorScale.ScaleAlpha(float32(options.Alpha))
+		}
+		screen.DrawImage(glyph.Image, f.op)
+		position += glyph.Advance
+	}
+}
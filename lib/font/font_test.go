@@ -0,0 +1,82 @@
+package font
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFont = `{
+  "image": "sheet",
+  "lineHeight": 10,
+  "glyphs": {
+    "A": {"x": 0, "y": 0, "w": 8, "h": 8, "advance": 8},
+    "V": {"x": 8, "y": 0, "w": 8, "h": 8, "advance": 8},
+    "I": {"x": 16, "y": 0, "w": 4, "h": 8, "advance": 4},
+    " ": {"x": 20, "y": 0, "w": 4, "h": 8, "advance": 4}
+  },
+  "kerning": {"AV": -2}
+}`
+
+func loadTestFont(t *testing.T) *Font {
+	t.Helper()
+	font, err := Load([]byte(testFont), map[string]*ebiten.Image{"sheet": ebiten.NewImage(32, 8)})
+	require.NoError(t, err)
+	return font
+}
+
+func TestLoadMissingImage(t *testing.T) {
+	_, err := Load([]byte(testFont), map[string]*ebiten.Image{})
+	assert.Error(t, err)
+}
+
+func TestWidth(t *testing.T) {
+	font := loadTestFont(t)
+	testCases := []struct {
+		text  string
+		width int
+	}{
+		{"", 0},
+		{"A", 8},
+		{"AI", 12},
+		{"AV", 14},
+		{"av", 14},
+		{"VA", 16},
+		{"A?A", 16},
+		{"A A", 20},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.width, font.Width(testCase.text), testCase.text)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	font := loadTestFont(t)
+	testCases := []struct {
+		text     string
+		maxWidth int
+		lines    []string
+	}{
+		{"AAA AAA", 0, []string{"AAA AAA"}},
+		{"AAA AAA", 100, []string{"AAA AAA"}},
+		{"AAA AAA", 40, []string{"AAA", "AAA"}},
+		{"AAAAAAAA I", 40, []string{"AAAAAAAA", "I"}},
+		{"A\nA I", 100, []string{"A", "A I"}},
+		{"I I I I I", 12, []string{"I I", "I I", "I"}},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.lines, font.Wrap(testCase.text, testCase.maxWidth), testCase.text)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	font := loadTestFont(t)
+	width, height := font.Measure("AAA AAA\nI", 0)
+	assert.Equal(t, 52, width)
+	assert.Equal(t, 20, height)
+	width, height = font.Measure("AAA AAA", 40)
+	assert.Equal(t, 24, width)
+	assert.Equal(t, 20, height)
+}
@@ -0,0 +1,35 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeout of a submission. Playing the replay again takes a moment on the server.
+const Timeout = 30 * time.Second
+
+// Submit a score to the leaderboard at url (e.g. http://host:port)
+func Submit(url string, submission Submission) (Entry, error) {
+	body, err := json.Marshal(submission)
+	if err != nil {
+		return Entry{}, err
+	}
+	client := &http.Client{Timeout: Timeout}
+	response, err := client.Post(strings.TrimSuffix(url, "/")+"/scores", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Entry{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return Entry{}, fmt.Errorf("leaderboard: %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	entry := Entry{}
+	err = json.NewDecoder(response.Body).Decode(&entry)
+	return entry, err
+}
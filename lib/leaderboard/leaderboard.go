@@ -0,0 +1,231 @@
+// Package leaderboard is a small HTTP service keeping the best scores. A score is submitted with the replay
+// of the game, and only accepted once the replay has been played again and gives the same result.
+//
+//	POST /scores        submit a score: {"name": "...", "replay": {...}}
+//	GET  /scores        the leaderboard as JSON
+//	GET  /replays/{id}  the replay of an entry
+//	GET  /              the leaderboard as an HTML page
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/replay"
+)
+
+const (
+	// MaxEntries is the number of entries kept on the leaderboard
+	MaxEntries = 100
+	// MaxNameLength is the maximum length of a name, in characters
+	MaxNameLength = 16
+	// MaxSubmissionSize is the maximum size of a submission, in bytes
+	MaxSubmissionSize = 8 << 20
+	boardFile         = "leaderboard.json"
+	replaysDir        = "replays"
+)
+
+var (
+	ErrInvalidName = errors.New("invalid name")
+	ErrNotListed   = errors.New("score too low for the leaderboard")
+	ErrDuplicate   = errors.New("replay already on the leaderboard")
+	ErrStorage     = errors.New("cannot save the leaderboard")
+)
+
+// Validator plays the replay again, and returns an error when the result is not the one claimed
+type Validator func(r *replay.Replay) error
+
+// Submission is a score sent to the leaderboard
+type Submission struct {
+	Name   string         `json:"name"`
+	Replay *replay.Replay `json:"replay"`
+}
+
+// Entry on the leaderboard
+type Entry struct {
+	ID      string    `json:"id"`
+	Rank    int       `json:"rank"`
+	Name    string    `json:"name"`
+	Score   int       `json:"score"`
+	Wave    int       `json:"wave"`
+	Players int       `json:"players"`
+	Frames  int       `json:"frames"`
+	Date    time.Time `json:"date"`
+	Digest  string    `json:"digest"` // of the game played, so a replay can't be submitted again under another name
+}
+
+// Board keeps the entries and their replays in a directory
+type Board struct {
+	dir        string
+	validate   Validator
+	validating sync.Mutex // replays are played one at a time
+	mutex      sync.Mutex // protects the entries and the files
+	entries    []Entry
+	nextID     int
+}
+
+// Open the leaderboard saved in dir, or start a new one
+func Open(dir string, validate Validator) (*Board, error) {
+	err := os.MkdirAll(filepath.Join(dir, replaysDir), 0o755)
+	if err != nil {
+		return nil, err
+	}
+	b := &Board{
+		dir:      dir,
+		validate: validate,
+		entries:  make([]Entry, 0, MaxEntries),
+	}
+	data, err := os.ReadFile(filepath.Join(dir, boardFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &b.entries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", boardFile, err)
+	}
+	for _, entry := range b.entries {
+		id, _ := strconv.Atoi(entry.ID)
+		b.nextID = max(b.nextID, id+1)
+	}
+	return b, nil
+}
+
+// Entries returns a copy of the leaderboard, best score first
+func (b *Board) Entries() []Entry {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	entries := make([]Entry, len(b.entries))
+	copy(entries, b.entries)
+	return entries
+}
+
+// Submit validates the replay of the submission, and adds it to the leaderboard
+func (b *Board) Submit(submission Submission) (Entry, error) {
+	name := strings.TrimSpace(submission.Name)
+	if name == "" || len([]rune(name)) > MaxNameLength {
+		return Entry{}, ErrInvalidName
+	}
+	r := submission.Replay
+	if r == nil {
+		return Entry{}, replay.ErrInvalidReplay
+	}
+	err := r.Validate()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// don't play a replay that can't make it to the leaderboard
+	digest := r.Digest()
+	b.mutex.Lock()
+	err = b.check(r.Score(), digest)
+	b.mutex.Unlock()
+	if err != nil {
+		return Entry{}, err
+	}
+	// replays are played one at a time, so a flood of submissions doesn't take all the CPU.
+	// The entries stay available meanwhile.
+	b.validating.Lock()
+	err = b.validate(r)
+	b.validating.Unlock()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	// the leaderboard may have changed during the validation
+	err = b.check(r.Score(), digest)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		ID:      strconv.Itoa(b.nextID),
+		Name:    name,
+		Score:   r.Score(),
+		Wave:    r.Wave,
+		Players: r.Players,
+		Frames:  r.Frames,
+		Date:    time.Now().UTC().Truncate(time.Second),
+		Digest:  digest,
+	}
+	err = b.saveReplay(entry.ID, r)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	b.nextID++
+	b.entries = append(b.entries, entry)
+	// the oldest entry stays first on a tie
+	sort.SliceStable(b.entries, func(i, j int) bool { return b.entries[i].Score > b.entries[j].Score })
+	for i := range b.entries {
+		b.entries[i].Rank = i + 1
+		if b.entries[i].ID == entry.ID {
+			entry.Rank = i + 1
+		}
+	}
+	if len(b.entries) > MaxEntries {
+		os.Remove(b.replayPath(b.entries[MaxEntries].ID))
+		b.entries = b.entries[:MaxEntries]
+	}
+	err = b.save()
+	if err != nil {
+		return entry, fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	return entry, nil
+}
+
+// check the score would make it to the leaderboard, and the game is not already on it. The mutex must be held.
+func (b *Board) check(score int, digest string) error {
+	if len(b.entries) == MaxEntries && score <= b.entries[len(b.entries)-1].Score {
+		return ErrNotListed
+	}
+	for _, entry := range b.entries {
+		if entry.Digest == digest {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (b *Board) replayPath(id string) string {
+	return filepath.Join(b.dir, replaysDir, id+".json")
+}
+
+func (b *Board) saveReplay(id string, r *replay.Replay) error {
+	file, err := os.Create(b.replayPath(id))
+	if err != nil {
+		return err
+	}
+	err = r.Write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// save the entries. The mutex must be held.
+func (b *Board) save() error {
+	data, err := json.MarshalIndent(b.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write then rename, so the file is never half written
+	path := filepath.Join(b.dir, boardFile)
+	err = os.WriteFile(path+".tmp", data, 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,196 @@
+package leaderboard
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/replay"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errCheated = errors.New("score doesn't match the replay")
+
+// testValidator accepts the replays claiming 10 points per frame
+func testValidator(r *replay.Replay) error {
+	if r.Score() != r.Frames*10 {
+		return errCheated
+	}
+	return nil
+}
+
+func testReplay(frames int) *replay.Replay {
+	r := &replay.Replay{Version: replay.Version, Seed: 1, Players: 1, Weapon: "cannon", Scores: []int{frames * 10}, Wave: frames / 10}
+	for i := 0; i < frames; i++ {
+		if replay.IsCheckpoint(i) {
+			r.Checkpoint(uint64(i))
+		}
+		r.Record([]rollback.Input{rollback.InputFire})
+	}
+	return r
+}
+
+func startServer(t *testing.T, dir string) (*Board, *httptest.Server) {
+	t.Helper()
+	board, err := Open(dir, testValidator)
+	require.NoError(t, err)
+	server := httptest.NewServer(board.Handler())
+	t.Cleanup(server.Close)
+	return board, server
+}
+
+func TestSubmit(t *testing.T) {
+	dir := t.TempDir()
+	_, server := startServer(t, dir)
+
+	entry, err := Submit(server.URL, Submission{Name: "ada", Replay: testReplay(20)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Rank)
+	assert.Equal(t, 200, entry.Score)
+	assert.Equal(t, 2, entry.Wave)
+
+	entry, err = Submit(server.URL, Submission{Name: " bob ", Replay: testReplay(50)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Rank)
+	assert.Equal(t, "bob", entry.Name)
+
+	// another game with the same score
+	tie := testReplay(20)
+	tie.Seed = 2
+	entry, err = Submit(server.URL, Submission{Name: "cy", Replay: tie})
+	require.NoError(t, err)
+	assert.Equal(t, 3, entry.Rank, "a tie goes after the older entry")
+
+	// the board is still there after a restart
+	board, err := Open(dir, testValidator)
+	require.NoError(t, err)
+	entries := board.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"bob", "ada", "cy"}, []string{entries[0].Name, entries[1].Name, entries[2].Name})
+	assert.Equal(t, []int{1, 2, 3}, []int{entries[0].Rank, entries[1].Rank, entries[2].Rank})
+
+	// and the replays are kept
+	response, err := http.Get(server.URL + "/replays/" + entries[0].ID)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	r, err := replay.Read(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, testReplay(50), r)
+}
+
+func TestSubmitRejected(t *testing.T) {
+	board, server := startServer(t, t.TempDir())
+
+	cheater := testReplay(20)
+	cheater.Scores[0] = 99999
+	incomplete := testReplay(20)
+	incomplete.Frames = 30
+
+	testData := []struct {
+		name       string
+		submission Submission
+	}{
+		{"cheater", Submission{Name: "eve", Replay: cheater}},
+		{"incomplete replay", Submission{Name: "eve", Replay: incomplete}},
+		{"no replay", Submission{Name: "eve"}},
+		{"no name", Submission{Name: "  ", Replay: testReplay(20)}},
+		{"long name", Submission{Name: "abcdefghijklmnopq", Replay: testReplay(20)}},
+	}
+	for _, testItem := range testData {
+		t.Run(testItem.name, func(t *testing.T) {
+			_, err := Submit(server.URL, testItem.submission)
+			assert.ErrorContains(t, err, "422")
+		})
+	}
+	assert.Empty(t, board.Entries())
+
+	_, err := board.Submit(Submission{Name: "eve", Replay: cheater})
+	assert.ErrorIs(t, err, errCheated)
+}
+
+func TestPages(t *testing.T) {
+	board, server := startServer(t, t.TempDir())
+	_, err := board.Submit(Submission{Name: "<ada>", Replay: testReplay(10)})
+	require.NoError(t, err)
+
+	response, err := http.Get(server.URL + "/")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	assert.Contains(t, string(body), "&lt;ada&gt;")
+	assert.Contains(t, string(body), "<td>100</td>")
+
+	response, err = http.Get(server.URL + "/scores")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+	for _, path := range []string{"/replays/12", "/replays/..%2Fleaderboard", "/unknown"} {
+		response, err = http.Get(server.URL + path)
+		require.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusNotFound, response.StatusCode, path)
+	}
+}
+
+func TestLeaderboardIsFull(t *testing.T) {
+	board, err := Open(t.TempDir(), testValidator)
+	require.NoError(t, err)
+	for i := 0; i < MaxEntries; i++ {
+		_, err := board.Submit(Submission{Name: "ada", Replay: testReplay(i + 10)})
+		require.NoError(t, err)
+	}
+	_, err = board.Submit(Submission{Name: "bob", Replay: testReplay(10)})
+	assert.ErrorIs(t, err, ErrNotListed)
+
+	entry, err := board.Submit(Submission{Name: "cy", Replay: testReplay(500)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Rank)
+	entries := board.Entries()
+	assert.Len(t, entries, MaxEntries)
+	assert.Equal(t, 110, entries[MaxEntries-1].Score)
+}
+
+func TestEntriesDuringValidation(t *testing.T) {
+	validating := make(chan struct{})
+	release := make(chan struct{})
+	board, err := Open(t.TempDir(), func(r *replay.Replay) error {
+		close(validating)
+		<-release
+		return testValidator(r)
+	})
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := board.Submit(Submission{Name: "ada", Replay: testReplay(20)})
+		done <- err
+	}()
+	<-validating
+	// the leaderboard is still available while a replay is played
+	assert.Empty(t, board.Entries())
+	close(release)
+	require.NoError(t, <-done)
+	assert.Len(t, board.Entries(), 1)
+}
+
+func TestSubmitCopiedReplay(t *testing.T) {
+	_, server := startServer(t, t.TempDir())
+	entry, err := Submit(server.URL, Submission{Name: "ada", Replay: testReplay(20)})
+	require.NoError(t, err)
+
+	// download the replay, and submit it again under another name
+	response, err := http.Get(server.URL + "/replays/" + entry.ID)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	copied, err := replay.Read(response.Body)
+	require.NoError(t, err)
+	_, err = Submit(server.URL, Submission{Name: "eve", Replay: copied})
+	assert.ErrorContains(t, err, "422")
+	assert.ErrorContains(t, err, ErrDuplicate.Error())
+}
@@ -0,0 +1,96 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+var page = template.Must(template.New("leaderboard").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Myriapod leaderboard</title>
+<style>
+body { font-family: sans-serif; background: #101020; color: #e0e0ff; }
+table { margin: 2em auto; border-collapse: collapse; }
+th, td { padding: 0.3em 1em; text-align: right; }
+td.name { text-align: left; }
+tr:nth-child(even) { background: #202040; }
+a { color: #80c0ff; }
+</style>
+</head>
+<body>
+<h1 style="text-align: center">Myriapod leaderboard</h1>
+<table>
+<tr><th>#</th><th>Name</th><th>Score</th><th>Wave</th><th>Players</th><th>Date</th><th></th></tr>
+{{range .}}<tr><td>{{.Rank}}</td><td class="name">{{.Name}}</td><td>{{.Score}}</td><td>{{.Wave}}</td><td>{{.Players}}</td><td>{{.Date.Format "2006-01-02"}}</td><td><a href="/replays/{{.ID}}">replay</a></td></tr>
+{{else}}<tr><td colspan="7">No score yet</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Handler returns the HTTP handler of the leaderboard
+func (b *Board) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scores", b.handleSubmit)
+	mux.HandleFunc("GET /scores", b.handleScores)
+	mux.HandleFunc("GET /replays/{id}", b.handleReplay)
+	mux.HandleFunc("GET /{$}", b.handlePage)
+	return mux
+}
+
+func (b *Board) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	submission := Submission{}
+	err := json.NewDecoder(io.LimitReader(r.Body, MaxSubmissionSize)).Decode(&submission)
+	if err != nil {
+		http.Error(w, "invalid submission: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry, err := b.Submit(submission)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, ErrStorage) {
+			status = http.StatusInternalServerError
+		}
+		log.Printf("score from %q refused: %v", submission.Name, err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	log.Printf("score %d from %q accepted at rank %d", entry.Score, entry.Name, entry.Rank)
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (b *Board) handleScores(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.Entries())
+}
+
+func (b *Board) handleReplay(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, b.replayPath(id))
+}
+
+func (b *Board) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := page.Execute(w, b.Entries())
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package particle is a small CPU particle system. Particles live in a buffer allocated once,
+// so emitting and updating particles doesn't allocate memory during the game.
+package particle
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Config describes how an emitter creates particles
+type Config struct {
+	Burst          int          // number of particles emitted straight away
+	Rate           int          // number of particles emitted per tick after the burst
+	Duration       int          // number of ticks the emitter keeps emitting at Rate
+	Lifetime       int          // number of ticks a particle lives
+	LifetimeSpread int          // random variation of the lifetime (+/-)
+	Speed          float64      // initial speed in pixels per tick
+	SpeedSpread    float64      // random variation of the speed (+/-)
+	Angle          float64      // direction of emission in radians (0 = right, Pi/2 = down)
+	AngleSpread    float64      // random variation of the direction (+/-). Use Pi for all directions
+	Gravity        float64      // added to the vertical speed every tick
+	Drag           float64      // fraction of the speed lost every tick
+	Size           float64      // size of a particle in pixels
+	EndSize        float64      // size at the end of its life (0 keeps the same size)
+	Colours        []color.RGBA // colour over life, not premultiplied: the particle goes through each colour in turn
+}
+
+type particle struct {
+	config   *Config
+	x, y     float64
+	vx, vy   float64
+	age      int
+	lifetime int
+}
+
+type emitter struct {
+	config *Config
+	x, y   float64
+	timer  int
+}
+
+// System holds all the particles and the emitters still running
+type System struct {
+	particles []particle
+	count     int
+	emitters  []emitter
+	random    *rand.Rand
+	image     *ebiten.Image
+	op        *ebiten.DrawImageOptions
+}
+
+// NewSystem creates a particle system with a maximum number of live particles.
+// When the buffer is full, new particles are dropped.
+func NewSystem(capacity int) *System {
+	return &System{
+		particles: make([]particle, capacity),
+		emitters:  make([]emitter, 0, 16),
+		// particles are only visual: they use their own random source so they don't change the game sequence
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		op:     &ebiten.DrawImageOptions{},
+	}
+}
+
+// Count returns the number of live particles
+func (s *System) Count() int {
+	return s.count
+}
+
+// Clear removes all particles and emitters
+func (s *System) Clear() {
+	s.count = 0
+	s.emitters = s.emitters[:0]
+}
+
+// Emit starts a new emitter at this position
+func (s *System) Emit(config *Config, x, y float64) {
+	for i := 0; i < config.Burst; i++ {
+		s.spawn(config, x, y)
+	}
+	if config.Rate > 0 && config.Duration > 0 {
+		s.emitters = append(s.emitters, emitter{config: config, x: x, y: y})
+	}
+}
+
+// Update moves all the particles and runs the emitters
+func (s *System) Update() {
+	// continuous emitters
+	running := s.emitters[:0]
+	for _, e := range s.emitters {
+		for i := 0; i < e.config.Rate; i++ {
+			s.spawn(e.config, e.x, e.y)
+		}
+		e.timer++
+		if e.timer < e.config.Duration {
+			running = append(running, e)
+		}
+	}
+	s.emitters = running
+
+	for i := 0; i < s.count; {
+		p := &s.particles[i]
+		p.age++
+		if p.age >= p.lifetime {
+			// swap with the last live particle
+			s.count--
+			s.particles[i] = s.particles[s.count]
+			continue
+		}
+		p.vy += p.config.Gravity
+		if p.config.Drag > 0 {
+			p.vx *= 1 - p.config.Drag
+			p.vy *= 1 - p.config.Drag
+		}
+		p.x += p.vx
+		p.y += p.vy
+		i++
+	}
+}
+
+// Draw all the particles
+func (s *System) Draw(screen *ebiten.Image) {
+	if s.count == 0 {
+		return
+	}
+	if s.image == nil {
+		s.image = ebiten.NewImage(1, 1)
+		s.image.Fill(color.White)
+	}
+	for i := 0; i < s.count; i++ {
+		p := &s.particles[i]
+		life := float64(p.age) / float64(p.lifetime)
+		size := p.config.Size
+		if p.config.EndSize > 0 {
+			size += (p.config.EndSize - size) * life
+		}
+		s.op.GeoM.Reset()
+		s.op.GeoM.Scale(size, size)
+		s.op.GeoM.Translate(p.x-size/2, p.y-size/2)
+		s.op.ColorScale.Reset()
+		// the colours are interpolated straight, then premultiplied by their alpha
+		colour := colourAt(p.config.Colours, life)
+		s.op.ColorScale.Scale(float32(colour.R)/0xff, float32(colour.G)/0xff, float32(colour.B)/0xff, 1)
+		s.op.ColorScale.ScaleAlpha(float32(colour.A) / 0xff)
+		screen.DrawImage(s.image, s.op)
+	}
+}
+
+func (s *System) spawn(config *Config, x, y float64) {
+	if s.count >= len(s.particles) {
+		return
+	}
+	angle := config.Angle + config.AngleSpread*(s.random.Float64()*2-1)
+	speed := config.Speed + config.SpeedSpread*(s.random.Float64()*2-1)
+	lifetime := config.Lifetime
+	if config.LifetimeSpread > 0 {
+		lifetime += s.random.Intn(config.LifetimeSpread*2+1) - config.LifetimeSpread
+	}
+	s.particles[s.count] = particle{
+		config:   config,
+		x:        x,
+		y:        y,
+		vx:       math.Cos(angle) * speed,
+		vy:       math.Sin(angle) * speed,
+		lifetime: max(lifetime, 1),
+	}
+	s.count++
+}
+
+// colourAt interpolates the list of colours at life (between 0 and 1)
+func colourAt(colours []color.RGBA, life float64) color.RGBA {
+	switch len(colours) {
+	case 0:
+		return color.RGBA{0xff, 0xff, 0xff, 0xff}
+	case 1:
+		return colours[0]
+	}
+	position := life * float64(len(colours)-1)
+	index := int(position)
+	if index >= len(colours)-1 {
+		return colours[len(colours)-1]
+	}
+	ratio := position - float64(index)
+	from, to := colours[index], colours[index+1]
+	return color.RGBA{
+		R: mix(from.R, to.R, ratio),
+		G: mix(from.G, to.G, ratio),
+		B: mix(from.B, to.B, ratio),
+		A: mix(from.A, to.A, ratio),
+	}
+}
+
+func mix(from, to uint8, ratio float64) uint8 {
+	return uint8(float64(from) + (float64(to)-float64(from))*ratio)
+}
@@ -0,0 +1,67 @@
+package particle
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurstLifetime(t *testing.T) {
+	system := NewSystem(100)
+	system.Emit(&Config{Burst: 10, Lifetime: 5, Speed: 1}, 0, 0)
+	assert.Equal(t, 10, system.Count())
+	for i := 0; i < 4; i++ {
+		system.Update()
+	}
+	assert.Equal(t, 10, system.Count())
+	system.Update()
+	assert.Equal(t, 0, system.Count())
+}
+
+func TestCapacity(t *testing.T) {
+	system := NewSystem(8)
+	system.Emit(&Config{Burst: 20, Lifetime: 5}, 0, 0)
+	assert.Equal(t, 8, system.Count())
+}
+
+func TestContinuousEmitter(t *testing.T) {
+	system := NewSystem(100)
+	system.Emit(&Config{Rate: 2, Duration: 3, Lifetime: 10}, 0, 0)
+	assert.Equal(t, 0, system.Count())
+	for i := 0; i < 5; i++ {
+		system.Update()
+	}
+	assert.Equal(t, 6, system.Count())
+	assert.Empty(t, system.emitters)
+}
+
+func TestGravity(t *testing.T) {
+	system := NewSystem(1)
+	system.Emit(&Config{Burst: 1, Lifetime: 10, Gravity: 1}, 0, 0)
+	for i := 0; i < 3; i++ {
+		system.Update()
+	}
+	// speed goes 1, 2, 3
+	assert.Equal(t, 6.0, system.particles[0].y)
+	assert.Equal(t, 0.0, system.particles[0].x)
+}
+
+func TestNoAllocation(t *testing.T) {
+	system := NewSystem(1000)
+	config := &Config{Burst: 50, Rate: 5, Duration: 10, Lifetime: 30, Speed: 2, AngleSpread: 3, Gravity: 0.1}
+	allocs := testing.AllocsPerRun(100, func() {
+		system.Emit(config, 10, 10)
+		system.Update()
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestColourAt(t *testing.T) {
+	colours := []color.RGBA{{0, 0, 0, 255}, {200, 100, 0, 255}, {200, 100, 200, 0}}
+	assert.Equal(t, colours[0], colourAt(colours, 0))
+	assert.Equal(t, color.RGBA{100, 50, 0, 255}, colourAt(colours, 0.25))
+	assert.Equal(t, colours[1], colourAt(colours, 0.5))
+	assert.Equal(t, colours[2], colourAt(colours, 1))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, colourAt(nil, 0.5))
+}
@@ -0,0 +1,50 @@
+// Package random is a small pseudo-random source whose whole state is a single number,
+// so the game can save it with a snapshot and restore it later (e.g. to re-simulate a few ticks).
+package random
+
+import "math/rand"
+
+// Source is a SplitMix64 generator. It implements rand.Source64.
+type Source struct {
+	state uint64
+}
+
+// NewSource returns a source seeded with seed
+func NewSource(seed int64) *Source {
+	return &Source{state: uint64(seed)}
+}
+
+// New returns a *rand.Rand reading from a new source, with the source itself to save and restore its state
+func New(seed int64) (*rand.Rand, *Source) {
+	source := NewSource(seed)
+	return rand.New(source), source
+}
+
+// Seed resets the generator
+func (s *Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns the next number of the sequence
+func (s *Source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative number
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// State returns the current state, to restore later with SetState
+func (s *Source) State() uint64 {
+	return s.state
+}
+
+// SetState restores a state returned by State
+func (s *Source) SetState(state uint64) {
+	s.state = state
+}
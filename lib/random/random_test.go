@@ -0,0 +1,36 @@
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameSeedSameSequence(t *testing.T) {
+	random1, _ := New(42)
+	random2, _ := New(42)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, random1.Int63(), random2.Int63())
+	}
+}
+
+func TestRestoreState(t *testing.T) {
+	random, source := New(7)
+	random.Intn(10)
+	state := source.State()
+	expected := []float64{random.Float64(), random.Float64(), random.Float64()}
+
+	source.SetState(state)
+	for _, value := range expected {
+		assert.Equal(t, value, random.Float64())
+	}
+}
+
+func TestRange(t *testing.T) {
+	random, _ := New(1)
+	for i := 0; i < 1000; i++ {
+		value := random.Intn(4)
+		assert.GreaterOrEqual(t, value, 0)
+		assert.Less(t, value, 4)
+	}
+}
@@ -0,0 +1,245 @@
+// Package replay records the inputs of a game, so it can be played again from the same seed.
+// A replay also holds the result claimed by the player, to be checked by playing it again, and a chain of
+// hashes of the game state: editing the inputs of a recorded game changes the state, and breaks the chain.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+)
+
+const (
+	// Version of the replay format
+	Version = 2
+	// HashInterval is the number of frames between two hashes of the game state
+	HashInterval = 60
+	// MaxFrames is the length of the longest game accepted: four hours at 60 ticks per second
+	MaxFrames = 4 * 60 * 60 * 60
+)
+
+var ErrInvalidReplay = errors.New("invalid replay")
+
+// Run is an input held for Count ticks. It's encoded as [input, count].
+type Run struct {
+	Input rollback.Input
+	Count int
+}
+
+func (r Run) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{int(r.Input), r.Count})
+}
+
+func (r *Run) UnmarshalJSON(data []byte) error {
+	var values [2]int
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+	if values[0] < 0 || values[0] > 0xff || values[1] <= 0 {
+		return fmt.Errorf("%w: input run %v", ErrInvalidReplay, values)
+	}
+	r.Input, r.Count = rollback.Input(values[0]), values[1]
+	return nil
+}
+
+// Replay is a recorded game: the options to start it again, the inputs of each tick, and the result
+type Replay struct {
+	Version        int    `json:"version"`
+	Seed           int64  `json:"seed"`
+	Players        int    `json:"players"`
+	Alternate      bool   `json:"alternate,omitempty"`
+	SharedLives    bool   `json:"sharedLives,omitempty"`
+	Weapon         string `json:"weapon"`
+	ExtraLifeEvery int    `json:"extraLifeEvery"`
+	ExtraLifeAt    []int  `json:"extraLifeAt,omitempty"`
+	// result claimed by the player
+	Scores []int `json:"scores"`
+	Wave   int   `json:"wave"`
+	Frames int   `json:"frames"`
+	// Inputs holds one list of runs for each player in play: all the players in co-op, the one in turn when alternating
+	Inputs [][]Run `json:"inputs"`
+	// Hashes of the game state at the start of every HashInterval frames, each one chained to the previous one
+	Hashes []uint64 `json:"hashes"`
+}
+
+// IsCheckpoint returns true when the state must be hashed at the start of this frame
+func IsCheckpoint(frame int) bool {
+	return frame%HashInterval == 0
+}
+
+// ChainHash returns the next hash of the chain
+func ChainHash(previous, state uint64) uint64 {
+	hash := fnv.New64a()
+	buffer := binary.LittleEndian.AppendUint64(nil, previous)
+	buffer = binary.LittleEndian.AppendUint64(buffer, state)
+	_, _ = hash.Write(buffer)
+	return hash.Sum64()
+}
+
+// Checkpoint adds the hash of the game state to the chain. It's called before recording a checkpoint frame.
+func (r *Replay) Checkpoint(state uint64) {
+	previous := uint64(0)
+	if len(r.Hashes) > 0 {
+		previous = r.Hashes[len(r.Hashes)-1]
+	}
+	r.Hashes = append(r.Hashes, ChainHash(previous, state))
+}
+
+// Digest returns a fingerprint of the game played: the seed, the options and the inputs.
+// Two replays of the same game have the same digest, whatever the result they claim.
+func (r *Replay) Digest() string {
+	game := *r
+	game.Scores, game.Wave, game.Frames, game.Hashes = nil, 0, 0, nil
+	hash := sha256.New()
+	_ = json.NewEncoder(hash).Encode(game)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Record the inputs of one more tick
+func (r *Replay) Record(inputs []rollback.Input) {
+	if r.Inputs == nil {
+		r.Inputs = make([][]Run, len(inputs))
+	}
+	for i, input := range inputs {
+		runs := r.Inputs[i]
+		if len(runs) > 0 && runs[len(runs)-1].Input == input {
+			runs[len(runs)-1].Count++
+			continue
+		}
+		r.Inputs[i] = append(runs, Run{Input: input, Count: 1})
+	}
+	r.Frames++
+}
+
+// Score returns the total claimed score
+func (r *Replay) Score() int {
+	total := 0
+	for _, score := range r.Scores {
+		total += score
+	}
+	return total
+}
+
+// Validate checks the replay is complete: all the lists of inputs cover every frame
+func (r *Replay) Validate() error {
+	if r.Version != Version {
+		return fmt.Errorf("%w: version %d, expected %d", ErrInvalidReplay, r.Version, Version)
+	}
+	if r.Frames < 0 || r.Frames > MaxFrames {
+		return fmt.Errorf("%w: %d frames", ErrInvalidReplay, r.Frames)
+	}
+	if r.Players < 1 || r.Players > rollback.Players {
+		return fmt.Errorf("%w: %d players", ErrInvalidReplay, r.Players)
+	}
+	streams := r.Players
+	if r.Alternate {
+		streams = 1
+	}
+	if len(r.Inputs) != streams {
+		return fmt.Errorf("%w: %d lists of inputs, expected %d", ErrInvalidReplay, len(r.Inputs), streams)
+	}
+	if len(r.Scores) != r.Players {
+		return fmt.Errorf("%w: %d scores for %d players", ErrInvalidReplay, len(r.Scores), r.Players)
+	}
+	if checkpoints := (r.Frames + HashInterval - 1) / HashInterval; len(r.Hashes) != checkpoints {
+		return fmt.Errorf("%w: %d hashes for %d frames, expected %d", ErrInvalidReplay, len(r.Hashes), r.Frames, checkpoints)
+	}
+	for i, runs := range r.Inputs {
+		frames := 0
+		for _, run := range runs {
+			frames += run.Count
+		}
+		if frames != r.Frames {
+			return fmt.Errorf("%w: inputs %d cover %d frames, expected %d", ErrInvalidReplay, i+1, frames, r.Frames)
+		}
+	}
+	return nil
+}
+
+// Reader returns the inputs of the replay tick after tick
+func (r *Replay) Reader() *Reader {
+	return &Reader{
+		replay:    r,
+		positions: make([]int, len(r.Inputs)),
+		used:      make([]int, len(r.Inputs)),
+		inputs:    make([]rollback.Input, len(r.Inputs)),
+	}
+}
+
+// Reader goes through the inputs of a replay
+type Reader struct {
+	replay    *Replay
+	positions []int // current run of each list
+	used      []int // ticks used in the current run
+	inputs    []rollback.Input
+}
+
+// Next returns the inputs of the next tick, or false at the end of the replay
+func (r *Reader) Next() ([]rollback.Input, bool) {
+	for i, runs := range r.replay.Inputs {
+		if r.positions[i] < len(runs) && r.used[i] == runs[r.positions[i]].Count {
+			r.positions[i]++
+			r.used[i] = 0
+		}
+		if r.positions[i] >= len(runs) {
+			return nil, false
+		}
+		r.inputs[i] = runs[r.positions[i]].Input
+		r.used[i]++
+	}
+	return r.inputs, len(r.inputs) > 0
+}
+
+// Checker returns the hash chain of the replay checkpoint after checkpoint
+func (r *Replay) Checker() *Checker {
+	return &Checker{replay: r}
+}
+
+// Checker compares the state of a game played again with the hash chain of the replay
+type Checker struct {
+	replay *Replay
+	chain  uint64
+	index  int
+}
+
+// Check the state of the next checkpoint. It returns false when it's not the state recorded.
+func (c *Checker) Check(state uint64) bool {
+	if c.index >= len(c.replay.Hashes) {
+		return false
+	}
+	c.chain = ChainHash(c.chain, state)
+	c.index++
+	return c.chain == c.replay.Hashes[c.index-1]
+}
+
+// Checked returns the number of checkpoints checked
+func (c *Checker) Checked() int {
+	return c.index
+}
+
+// Write the replay as JSON
+func (r *Replay) Write(writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(r)
+}
+
+// Read a replay written by Write, and validate it
+func Read(reader io.Reader) (*Replay, error) {
+	r := &Replay{}
+	err := json.NewDecoder(reader).Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReplay, err)
+	}
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
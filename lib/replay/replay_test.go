@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordTestReplay() (*Replay, [][]rollback.Input) {
+	r := &Replay{Version: Version, Seed: 42, Players: 2, Weapon: "cannon", Scores: []int{100, 250}, Wave: 3}
+	ticks := make([][]rollback.Input, 0)
+	for tick := 0; tick < 200; tick++ {
+		inputs := []rollback.Input{rollback.Input(tick / 10 % 4), rollback.InputFire}
+		if tick%50 == 0 {
+			inputs[1] = rollback.InputLeft
+		}
+		if IsCheckpoint(tick) {
+			r.Checkpoint(uint64(tick))
+		}
+		r.Record(inputs)
+		ticks = append(ticks, inputs)
+	}
+	return r, ticks
+}
+
+func TestRecordAndRead(t *testing.T) {
+	r, ticks := recordTestReplay()
+	assert.Equal(t, 200, r.Frames)
+	assert.Equal(t, 350, r.Score())
+	require.NoError(t, r.Validate())
+	// inputs are compressed as runs
+	assert.Less(t, len(r.Inputs[0]), 30)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, r.Write(buffer))
+	read, err := Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, r, read)
+
+	reader := read.Reader()
+	for tick, expected := range ticks {
+		inputs, ok := reader.Next()
+		require.True(t, ok, "tick %d", tick)
+		assert.Equal(t, expected, inputs, "tick %d", tick)
+	}
+	_, ok := reader.Next()
+	assert.False(t, ok)
+}
+
+func TestInvalidReplay(t *testing.T) {
+	testData := []struct {
+		name   string
+		change func(r *Replay)
+	}{
+		{"version", func(r *Replay) { r.Version = 99 }},
+		{"no player", func(r *Replay) { r.Players = 0 }},
+		{"missing inputs", func(r *Replay) { r.Inputs = r.Inputs[:1] }},
+		{"alternate with two lists of inputs", func(r *Replay) { r.Alternate = true }},
+		{"missing score", func(r *Replay) { r.Scores = r.Scores[:1] }},
+		{"more frames than inputs", func(r *Replay) { r.Frames++ }},
+		{"missing hash", func(r *Replay) { r.Hashes = r.Hashes[1:] }},
+		{"too long", func(r *Replay) { r.Frames = MaxFrames + 1 }},
+	}
+
+	for _, testItem := range testData {
+		t.Run(testItem.name, func(t *testing.T) {
+			r, _ := recordTestReplay()
+			testItem.change(r)
+			assert.ErrorIs(t, r.Validate(), ErrInvalidReplay)
+		})
+	}
+
+	_, err := Read(strings.NewReader(`{"version": 1, "inputs": [[[1, 0]]]}`))
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+	_, err = Read(strings.NewReader(`not json`))
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+}
+
+func TestHashChain(t *testing.T) {
+	r, _ := recordTestReplay()
+	require.Len(t, r.Hashes, 4)
+
+	checker := r.Checker()
+	for tick := 0; tick < r.Frames; tick += HashInterval {
+		assert.True(t, checker.Check(uint64(tick)), "tick %d", tick)
+	}
+	assert.Equal(t, 4, checker.Checked())
+	// no more hash
+	assert.False(t, checker.Check(240))
+
+	// once a state differs, the rest of the chain does too
+	checker = r.Checker()
+	assert.True(t, checker.Check(0))
+	assert.False(t, checker.Check(61))
+	assert.False(t, checker.Check(120))
+	assert.False(t, checker.Check(180))
+}
+
+func TestDigest(t *testing.T) {
+	r, _ := recordTestReplay()
+	digest := r.Digest()
+	assert.Len(t, digest, 64)
+
+	// the same game claiming another result
+	copied, _ := recordTestReplay()
+	copied.Scores = []int{1, 2}
+	copied.Wave = 9
+	assert.Equal(t, digest, copied.Digest())
+
+	// another game
+	other, _ := recordTestReplay()
+	other.Seed++
+	assert.NotEqual(t, digest, other.Digest())
+	other, _ = recordTestReplay()
+	other.Inputs[1][0].Input ^= rollback.InputFire
+	assert.NotEqual(t, digest, other.Digest())
+}
@@ -0,0 +1,86 @@
+package rollback
+
+import "math/rand"
+
+// Link is an in-memory network between two transports, to run two sessions in the same process.
+// Packets are delivered after a latency counted in ticks, and some of them are lost.
+type Link struct {
+	latency int
+	jitter  int
+	loss    float64
+	random  *rand.Rand
+	tick    int
+	ends    [2]*loopback
+}
+
+type delayedPacket struct {
+	tick int
+	data []byte
+}
+
+type loopback struct {
+	link    *Link
+	side    int
+	packets []delayedPacket
+	closed  bool
+}
+
+// NewLink creates a link delivering packets after latency ticks, plus up to jitter ticks (so they can arrive out of order).
+// loss is the ratio of packets lost. The seed makes the network behave the same every time.
+func NewLink(latency, jitter int, loss float64, seed int64) *Link {
+	l := &Link{
+		latency: latency,
+		jitter:  jitter,
+		loss:    loss,
+		random:  rand.New(rand.NewSource(seed)),
+	}
+	for side := range l.ends {
+		l.ends[side] = &loopback{link: l, side: side}
+	}
+	return l
+}
+
+// Transports returns both ends of the link
+func (l *Link) Transports() (Transport, Transport) {
+	return l.ends[0], l.ends[1]
+}
+
+// Tick moves the time forward
+func (l *Link) Tick() {
+	l.tick++
+}
+
+func (e *loopback) Send(packet []byte) error {
+	link := e.link
+	if link.random.Float64() < link.loss {
+		return nil
+	}
+	peer := link.ends[1-e.side]
+	if peer.closed {
+		return nil
+	}
+	delay := link.latency
+	if link.jitter > 0 {
+		delay += link.random.Intn(link.jitter + 1)
+	}
+	data := make([]byte, len(packet))
+	copy(data, packet)
+	peer.packets = append(peer.packets, delayedPacket{tick: link.tick + delay, data: data})
+	return nil
+}
+
+func (e *loopback) Receive() ([]byte, error) {
+	for i, packet := range e.packets {
+		if packet.tick <= e.link.tick {
+			e.packets = append(e.packets[:i], e.packets[i+1:]...)
+			return packet.data, nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *loopback) Close() error {
+	e.closed = true
+	e.packets = nil
+	return nil
+}
@@ -0,0 +1,62 @@
+package rollback
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	packetMagic = 0x52
+	// maxPacketInputs is the maximum number of inputs sent in one packet
+	maxPacketInputs = 64
+	packetHeader    = 1 + 4 + 1
+	packetFooter    = 4 + 4 + 8
+	maxPacketSize   = packetHeader + maxPacketInputs + packetFooter
+)
+
+var ErrInvalidPacket = errors.New("invalid rollback packet")
+
+// packet carries all the inputs not yet acknowledged by the peer, so a lost packet is covered by the next one
+type packet struct {
+	start    int     // tick of the first input
+	inputs   []Input // inputs from start
+	ack      int     // number of consecutive inputs received from the peer
+	hashTick int     // last tick confirmed by the sender, -1 if none
+	hash     uint64  // state hash after hashTick
+}
+
+func (p packet) encode() []byte {
+	buffer := make([]byte, 0, packetHeader+len(p.inputs)+packetFooter)
+	buffer = append(buffer, packetMagic)
+	buffer = binary.BigEndian.AppendUint32(buffer, uint32(p.start))
+	buffer = append(buffer, byte(len(p.inputs)))
+	for _, input := range p.inputs {
+		buffer = append(buffer, byte(input))
+	}
+	buffer = binary.BigEndian.AppendUint32(buffer, uint32(p.ack))
+	buffer = binary.BigEndian.AppendUint32(buffer, uint32(int32(p.hashTick)))
+	buffer = binary.BigEndian.AppendUint64(buffer, p.hash)
+	return buffer
+}
+
+func decodePacket(data []byte) (packet, error) {
+	if len(data) < packetHeader+packetFooter || data[0] != packetMagic {
+		return packet{}, ErrInvalidPacket
+	}
+	count := int(data[5])
+	if len(data) != packetHeader+count+packetFooter {
+		return packet{}, ErrInvalidPacket
+	}
+	p := packet{
+		start:  int(binary.BigEndian.Uint32(data[1:])),
+		inputs: make([]Input, count),
+	}
+	for i := range p.inputs {
+		p.inputs[i] = Input(data[packetHeader+i])
+	}
+	footer := data[packetHeader+count:]
+	p.ack = int(binary.BigEndian.Uint32(footer))
+	p.hashTick = int(int32(binary.BigEndian.Uint32(footer[4:])))
+	p.hash = binary.BigEndian.Uint64(footer[8:])
+	return p, nil
+}
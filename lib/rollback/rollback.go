@@ -0,0 +1,54 @@
+// Package rollback runs a two player game over the network without waiting for the other player:
+// each peer predicts the remote input, and when the real input arrives and differs from the prediction,
+// the game is loaded back to the tick of the misprediction and simulated again up to the present.
+//
+// The game must be deterministic: the same inputs applied to the same state always give the same state.
+// Both peers send a hash of their state with their inputs so a desync can be detected.
+package rollback
+
+import "time"
+
+const (
+	// Players is the number of players in a session, one on each peer
+	Players = 2
+	// MaxPrediction is the number of ticks a peer can run ahead of the last input received from the other one
+	MaxPrediction = 8
+	// MaxInputDelay is the maximum number of ticks a local input can be delayed
+	MaxInputDelay = 8
+	// historySize is the number of ticks of inputs, snapshots and hashes kept by a session
+	historySize = 128
+	// PeerTimeout is how long without any packet before the peer is considered gone
+	PeerTimeout = 5 * time.Second
+)
+
+// Input is the state of the controls of one player during one tick
+type Input uint8
+
+const (
+	InputLeft Input = 1 << iota
+	InputRight
+	InputUp
+	InputDown
+	InputFire
+)
+
+// Game is the simulation run by a session
+type Game interface {
+	// Step advances the game by one tick with the inputs of all the players.
+	// replay is true when the tick is simulated again after a rollback: sounds and visual effects should be muted.
+	Step(inputs []Input, replay bool)
+	// Save returns a snapshot of the whole game state
+	Save() any
+	// Load restores a snapshot returned by Save
+	Load(snapshot any)
+	// Hash returns a hash of the game state, to compare with the peer's
+	Hash() uint64
+}
+
+// Transport sends packets to the peer. Packets can be lost, duplicated or arrive out of order.
+type Transport interface {
+	Send(packet []byte) error
+	// Receive returns the next packet from the peer, or nil when there's none waiting. It never blocks.
+	Receive() ([]byte, error)
+	Close() error
+}
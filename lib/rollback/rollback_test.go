@@ -0,0 +1,270 @@
+package rollback
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	tick   int
+	x, y   [Players]int
+	shots  [Players]int
+	random uint64
+}
+
+// testGame is a small deterministic game
+type testGame struct {
+	state testState
+	// diverge makes the game different from tick divergeAt
+	diverge   bool
+	divergeAt int
+	replays   int
+}
+
+func (g *testGame) Step(inputs []Input, replay bool) {
+	if replay {
+		g.replays++
+	}
+	for player, input := range inputs {
+		if input&InputLeft != 0 {
+			g.state.x[player]--
+		}
+		if input&InputRight != 0 {
+			g.state.x[player]++
+		}
+		if input&InputUp != 0 {
+			g.state.y[player]--
+		}
+		if input&InputDown != 0 {
+			g.state.y[player]++
+		}
+		if input&InputFire != 0 && g.state.random%3 == 0 {
+			g.state.shots[player]++
+		}
+		g.state.random = g.state.random*6364136223846793005 + uint64(input) + 1442695040888963407
+	}
+	g.state.tick++
+	if g.diverge && g.state.tick == g.divergeAt {
+		g.state.x[0]++
+	}
+}
+
+func (g *testGame) Save() any {
+	return g.state
+}
+
+func (g *testGame) Load(snapshot any) {
+	g.state = snapshot.(testState)
+}
+
+func (g *testGame) Hash() uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v", g.state)
+	return h.Sum64()
+}
+
+// testInput returns the input of a player at a tick: it changes every few ticks so predictions are sometimes wrong
+func testInput(player, tick int) Input {
+	return Input((tick/7 + player*3) * 2654435761 >> 8 % 32)
+}
+
+// reference runs the game offline with the inputs the sessions receive
+func reference(ticks, delay int) *testGame {
+	game := &testGame{}
+	inputs := make([]Input, Players)
+	for tick := 0; tick < ticks; tick++ {
+		for player := range inputs {
+			inputs[player] = 0
+			if tick >= delay {
+				inputs[player] = testInput(player, tick-delay)
+			}
+		}
+		game.Step(inputs, false)
+	}
+	return game
+}
+
+// run two sessions until both of them have confirmed all the ticks
+func run(t *testing.T, sessions [Players]*Session, ticks int, tick func()) {
+	t.Helper()
+	for step := 0; step < ticks*20; step++ {
+		if sessions[0].Confirmed() >= ticks && sessions[1].Confirmed() >= ticks {
+			return
+		}
+		for player, session := range sessions {
+			if session.Frame() < ticks {
+				_, err := session.Advance(testInput(player, session.Frame()))
+				require.NoError(t, err)
+			} else {
+				require.NoError(t, session.Poll())
+			}
+		}
+		tick()
+	}
+	t.Fatalf("sessions confirmed %d and %d ticks out of %d", sessions[0].Confirmed(), sessions[1].Confirmed(), ticks)
+}
+
+func newSessions(t *testing.T, games [Players]*testGame, transports [Players]Transport, delay int) [Players]*Session {
+	t.Helper()
+	var sessions [Players]*Session
+	for player := range sessions {
+		session, err := NewSession(games[player], transports[player], player, delay)
+		require.NoError(t, err)
+		sessions[player] = session
+	}
+	return sessions
+}
+
+func TestSessionsConverge(t *testing.T) {
+	const ticks = 600
+	testData := []struct {
+		name    string
+		latency int
+		jitter  int
+		loss    float64
+		delay   int
+	}{
+		{"local", 0, 0, 0, 0},
+		{"latency", 3, 0, 0, 0},
+		{"latency with input delay", 3, 0, 0, 2},
+		{"jitter and loss", 3, 3, .2, 1},
+		{"high latency", 10, 2, .1, 2},
+		{"heavy loss", 2, 1, .5, 0},
+	}
+
+	for _, testItem := range testData {
+		t.Run(testItem.name, func(t *testing.T) {
+			link := NewLink(testItem.latency, testItem.jitter, testItem.loss, 1)
+			a, b := link.Transports()
+			games := [Players]*testGame{{}, {}}
+			sessions := newSessions(t, games, [Players]Transport{a, b}, testItem.delay)
+			run(t, sessions, ticks, link.Tick)
+
+			expected := reference(ticks, testItem.delay).Hash()
+			for player, game := range games {
+				assert.Equal(t, ticks, game.state.tick)
+				assert.Equal(t, expected, game.Hash(), "player %d", player)
+				assert.Equal(t, -1, sessions[player].Desync())
+			}
+			if testItem.latency > 0 {
+				assert.Greater(t, sessions[0].Rollbacks()+sessions[1].Rollbacks(), 0)
+				assert.Greater(t, games[0].replays+games[1].replays, 0)
+			}
+		})
+	}
+}
+
+func TestSessionDetectsDesync(t *testing.T) {
+	link := NewLink(2, 1, .1, 1)
+	a, b := link.Transports()
+	games := [Players]*testGame{{}, {diverge: true, divergeAt: 100}}
+	sessions := newSessions(t, games, [Players]Transport{a, b}, 1)
+	run(t, sessions, 300, link.Tick)
+
+	// the state after tick 99 is the first one to differ
+	assert.Equal(t, 99, sessions[0].Desync())
+	assert.Equal(t, 99, sessions[1].Desync())
+}
+
+func TestSessionWaitsForPeer(t *testing.T) {
+	const delay = 2
+	link := NewLink(0, 0, 0, 1)
+	a, _ := link.Transports()
+	session, err := NewSession(&testGame{}, a, 0, delay)
+	require.NoError(t, err)
+
+	for tick := 0; tick < delay+MaxPrediction; tick++ {
+		advanced, err := session.Advance(0)
+		require.NoError(t, err)
+		assert.True(t, advanced)
+		link.Tick()
+	}
+	advanced, err := session.Advance(0)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+	assert.Equal(t, delay+MaxPrediction, session.Frame())
+	assert.Equal(t, delay, session.Confirmed())
+}
+
+func TestSessionPeerLost(t *testing.T) {
+	link := NewLink(1, 0, 0, 1)
+	a, b := link.Transports()
+	sessions := newSessions(t, [Players]*testGame{{}, {}}, [Players]Transport{a, b}, 0)
+	clock := time.Now()
+	for _, session := range sessions {
+		session.now = func() time.Time { return clock }
+	}
+
+	// the peer may not have started yet
+	clock = clock.Add(2 * PeerTimeout)
+	require.NoError(t, sessions[0].Poll())
+	assert.False(t, sessions[0].PeerLost())
+
+	run(t, sessions, 30, link.Tick)
+	assert.False(t, sessions[0].PeerLost())
+
+	// the peer is gone, once the packets on the way are received
+	sessions[1].Close()
+	for range 10 {
+		require.NoError(t, sessions[0].Poll())
+		link.Tick()
+	}
+	clock = clock.Add(PeerTimeout / 2)
+	require.NoError(t, sessions[0].Poll())
+	assert.False(t, sessions[0].PeerLost())
+	clock = clock.Add(PeerTimeout)
+	require.NoError(t, sessions[0].Poll())
+	assert.True(t, sessions[0].PeerLost())
+}
+
+func TestNewSessionErrors(t *testing.T) {
+	link := NewLink(0, 0, 0, 1)
+	a, _ := link.Transports()
+	_, err := NewSession(&testGame{}, a, 2, 0)
+	assert.Error(t, err)
+	_, err = NewSession(&testGame{}, a, 0, MaxInputDelay+1)
+	assert.Error(t, err)
+}
+
+func TestPacket(t *testing.T) {
+	p := packet{start: 12, inputs: []Input{0, InputLeft, InputFire | InputUp}, ack: 10, hashTick: 9, hash: 0x1234567890abcdef}
+	decoded, err := decodePacket(p.encode())
+	require.NoError(t, err)
+	assert.Equal(t, p, decoded)
+
+	p = packet{start: 0, inputs: []Input{}, hashTick: -1}
+	decoded, err = decodePacket(p.encode())
+	require.NoError(t, err)
+	assert.Equal(t, p, decoded)
+
+	_, err = decodePacket([]byte("hello"))
+	assert.ErrorIs(t, err, ErrInvalidPacket)
+	_, err = decodePacket(p.encode()[1:])
+	assert.ErrorIs(t, err, ErrInvalidPacket)
+}
+
+func TestUDP(t *testing.T) {
+	var transports [Players]*UDP
+	for player := range transports {
+		transport, err := ListenUDP("127.0.0.1:0")
+		require.NoError(t, err)
+		defer transport.Close()
+		transports[player] = transport
+	}
+	require.NoError(t, transports[0].Connect(transports[1].Addr().String()))
+	require.NoError(t, transports[1].Connect(transports[0].Addr().String()))
+
+	const ticks = 120
+	games := [Players]*testGame{{}, {}}
+	sessions := newSessions(t, games, [Players]Transport{transports[0], transports[1]}, 1)
+	run(t, sessions, ticks, func() { time.Sleep(time.Millisecond) })
+
+	expected := reference(ticks, 1).Hash()
+	assert.Equal(t, expected, games[0].Hash())
+	assert.Equal(t, expected, games[1].Hash())
+}
@@ -0,0 +1,249 @@
+package rollback
+
+import (
+	"fmt"
+	"time"
+)
+
+type peerHash struct {
+	tick int
+	hash uint64
+}
+
+// Session runs the game on one peer
+type Session struct {
+	game         Game
+	transport    Transport
+	local        int // index of the local player
+	remote       int
+	delay        int
+	frame        int                         // next tick to simulate
+	inputs       [Players][historySize]Input // inputs by tick
+	received     [Players]int                // number of consecutive ticks with a known input, for each player
+	used         [historySize]Input          // remote input used to simulate each tick, real or predicted
+	snapshots    [historySize]any            // state before each tick
+	hashes       [historySize]uint64         // state hash after each tick
+	mismatch     int                         // first tick simulated with a wrong prediction, -1 if none
+	ack          int                         // number of local inputs received by the peer
+	peer         [historySize]peerHash       // hashes received from the peer
+	checked      int                         // ticks compared with the peer's hashes
+	desync       int
+	rollbacks    int
+	lastReceived time.Time        // when the last packet from the peer arrived, zero before the first one
+	now          func() time.Time // clock of the peer timeout
+}
+
+// NewSession starts a session at tick 0 of game. local is the index of the player on this peer (0 or 1).
+// The local inputs are delayed by delay ticks, which hides that much latency without any rollback.
+// Both peers must use the same delay.
+func NewSession(game Game, transport Transport, local, delay int) (*Session, error) {
+	if local < 0 || local >= Players {
+		return nil, fmt.Errorf("invalid local player %d", local)
+	}
+	if delay < 0 || delay > MaxInputDelay {
+		return nil, fmt.Errorf("input delay %d is out of range 0-%d", delay, MaxInputDelay)
+	}
+	s := &Session{
+		game:      game,
+		transport: transport,
+		local:     local,
+		remote:    1 - local,
+		delay:     delay,
+		mismatch:  -1,
+		desync:    -1,
+		now:       time.Now,
+	}
+	for i := range s.peer {
+		s.peer[i].tick = -1
+	}
+	// no one has any input during the first ticks of delay
+	for player := range s.received {
+		s.received[player] = delay
+	}
+	return s, nil
+}
+
+// Advance simulates one more tick with the local input. It returns false when the session is too far
+// ahead of the peer: the tick is not simulated and the input is dropped.
+func (s *Session) Advance(input Input) (bool, error) {
+	err := s.receive()
+	if err != nil {
+		return false, err
+	}
+	s.rollback()
+	if s.frame-s.received[s.remote] >= MaxPrediction {
+		// waiting for the peer
+		return false, s.send()
+	}
+	tick := s.frame + s.delay
+	s.inputs[s.local][tick%historySize] = input
+	s.received[s.local] = tick + 1
+	s.step(false)
+	s.check()
+	return true, s.send()
+}
+
+// Poll processes the packets from the peer without advancing the game
+func (s *Session) Poll() error {
+	err := s.receive()
+	if err != nil {
+		return err
+	}
+	s.rollback()
+	s.check()
+	return s.send()
+}
+
+// Frame returns the number of ticks simulated
+func (s *Session) Frame() int {
+	return s.frame
+}
+
+// Confirmed returns the number of ticks simulated with the real inputs of both players
+func (s *Session) Confirmed() int {
+	return min(s.frame, s.received[0], s.received[1])
+}
+
+// Rollbacks returns the number of times the game was loaded back and simulated again
+func (s *Session) Rollbacks() int {
+	return s.rollbacks
+}
+
+// Desync returns the first tick where the state differs from the peer's, or -1 if there's no desync
+func (s *Session) Desync() int {
+	return s.desync
+}
+
+// PeerLost returns true when nothing was received from the peer for PeerTimeout. The peer is not
+// considered lost before its first packet: it may not have started yet.
+func (s *Session) PeerLost() bool {
+	return !s.lastReceived.IsZero() && s.now().Sub(s.lastReceived) > PeerTimeout
+}
+
+// Close the transport
+func (s *Session) Close() error {
+	return s.transport.Close()
+}
+
+// step simulates the tick at s.frame
+func (s *Session) step(replay bool) {
+	tick := s.frame
+	index := tick % historySize
+	s.snapshots[index] = s.game.Save()
+	inputs := make([]Input, Players)
+	for player := range inputs {
+		inputs[player] = s.input(player, tick)
+	}
+	s.used[index] = inputs[s.remote]
+	s.game.Step(inputs, replay)
+	s.hashes[index] = s.game.Hash()
+	s.frame++
+}
+
+// input returns the input of player at tick, or the prediction when it hasn't been received yet:
+// most of the time a player keeps the same input from one tick to the next.
+func (s *Session) input(player, tick int) Input {
+	if tick < s.received[player] {
+		return s.inputs[player][tick%historySize]
+	}
+	if s.received[player] == 0 {
+		return 0
+	}
+	return s.inputs[player][(s.received[player]-1)%historySize]
+}
+
+// rollback loads the game back to the first mispredicted tick, and simulates it again up to the present
+func (s *Session) rollback() {
+	if s.mismatch < 0 {
+		return
+	}
+	end := s.frame
+	s.game.Load(s.snapshots[s.mismatch%historySize])
+	s.frame = s.mismatch
+	s.mismatch = -1
+	for s.frame < end {
+		s.step(true)
+	}
+	s.rollbacks++
+}
+
+// check compares the hashes received from the peer with the local ones, once the ticks are confirmed here too
+func (s *Session) check() {
+	confirmed := s.Confirmed()
+	for ; s.checked < confirmed; s.checked++ {
+		peer := s.peer[s.checked%historySize]
+		if peer.tick == s.checked {
+			s.compare(peer.tick, peer.hash)
+		}
+	}
+}
+
+// compare the peer's hash after a tick confirmed on both sides
+func (s *Session) compare(tick int, hash uint64) {
+	if hash != s.hashes[tick%historySize] && (s.desync < 0 || tick < s.desync) {
+		s.desync = tick
+	}
+}
+
+func (s *Session) receive() error {
+	for {
+		data, err := s.transport.Receive()
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			return nil
+		}
+		p, err := decodePacket(data)
+		if err != nil {
+			// not a packet from the peer
+			continue
+		}
+		s.receivePacket(p)
+	}
+}
+
+func (s *Session) receivePacket(p packet) {
+	s.lastReceived = s.now()
+	for i, input := range p.inputs {
+		tick := p.start + i
+		if tick < s.received[s.remote] {
+			// already received
+			continue
+		}
+		if tick > s.received[s.remote] {
+			// missing inputs: this packet arrived out of order
+			break
+		}
+		s.inputs[s.remote][tick%historySize] = input
+		s.received[s.remote]++
+		if tick < s.frame && s.used[tick%historySize] != input && (s.mismatch < 0 || tick < s.mismatch) {
+			s.mismatch = tick
+		}
+	}
+	s.ack = max(s.ack, p.ack)
+	if p.hashTick >= s.checked {
+		// compared once confirmed here too
+		s.peer[p.hashTick%historySize] = peerHash{tick: p.hashTick, hash: p.hash}
+	} else if p.hashTick >= 0 && p.hashTick >= s.frame-historySize {
+		s.compare(p.hashTick, p.hash)
+	}
+}
+
+// send the local inputs not acknowledged yet
+func (s *Session) send() error {
+	start := max(s.ack, s.received[s.local]-maxPacketInputs)
+	p := packet{
+		start:    start,
+		inputs:   make([]Input, s.received[s.local]-start),
+		ack:      s.received[s.remote],
+		hashTick: s.Confirmed() - 1,
+	}
+	for i := range p.inputs {
+		p.inputs[i] = s.inputs[s.local][(start+i)%historySize]
+	}
+	if p.hashTick >= 0 {
+		p.hash = s.hashes[p.hashTick%historySize]
+	}
+	return s.transport.Send(p.encode())
+}
@@ -0,0 +1,99 @@
+package rollback
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+// receiveQueue is the number of packets waiting to be processed before new ones are dropped
+const receiveQueue = 256
+
+// UDP sends the packets over UDP
+type UDP struct {
+	conn     *net.UDPConn
+	peer     atomic.Pointer[net.UDPAddr]
+	received chan []byte
+}
+
+// ListenUDP opens a UDP socket on the local address (host:port). Connect must be called before sending packets.
+func ListenUDP(local string) (*UDP, error) {
+	addr, err := net.ResolveUDPAddr("udp", local)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	u := &UDP{
+		conn:     conn,
+		received: make(chan []byte, receiveQueue),
+	}
+	go u.read()
+	return u, nil
+}
+
+// Connect sets the address (host:port) of the peer
+func (u *UDP) Connect(peer string) error {
+	addr, err := net.ResolveUDPAddr("udp", peer)
+	if err != nil {
+		return err
+	}
+	u.peer.Store(addr)
+	return nil
+}
+
+// Addr returns the local address
+func (u *UDP) Addr() net.Addr {
+	return u.conn.LocalAddr()
+}
+
+func (u *UDP) Send(packet []byte) error {
+	peer := u.peer.Load()
+	if peer == nil {
+		return errors.New("no peer address")
+	}
+	_, err := u.conn.WriteToUDP(packet, peer)
+	return err
+}
+
+func (u *UDP) Receive() ([]byte, error) {
+	select {
+	case packet := <-u.received:
+		return packet, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (u *UDP) Close() error {
+	return u.conn.Close()
+}
+
+// read the packets in the background, so Receive never blocks
+func (u *UDP) read() {
+	buffer := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := u.conn.ReadFromUDP(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// e.g. the peer is not listening yet (ICMP port unreachable): keep reading, a peer gone
+			// for good is detected by the session timeout
+			continue
+		}
+		if peer := u.peer.Load(); peer == nil || !addr.IP.Equal(peer.IP) || addr.Port != peer.Port {
+			// not from the peer
+			continue
+		}
+		packet := make([]byte, n)
+		copy(packet, buffer[:n])
+		select {
+		case u.received <- packet:
+		default:
+			// the game is not keeping up: the inputs are sent again with the next packets anyway
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package spectate
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Client receives the state of a game streamed by a server
+type Client struct {
+	conn  net.Conn
+	mutex sync.Mutex
+	state State
+	err   error
+}
+
+// Dial connects to the server at addr (host:port)
+func Dial(addr string, version int) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, Timeout)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	header := make([]byte, len(magic)+1)
+	conn.SetReadDeadline(time.Now().Add(Timeout))
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic {
+		conn.Close()
+		return nil, ErrInvalidFrame
+	}
+	if server := int(header[len(magic)]); server != version {
+		conn.Close()
+		return nil, VersionError{Client: version, Server: server}
+	}
+	conn.SetReadDeadline(time.Time{})
+	c := &Client{conn: conn}
+	go c.receive(reader)
+	return c, nil
+}
+
+// State returns the last state received. The error is set once the stream has stopped (io.EOF when the server closed it).
+func (c *Client) State() (State, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state.Clone(), c.err
+}
+
+// Close the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) receive(reader *bufio.Reader) {
+	var err error
+	for err == nil {
+		err = c.receiveFrame(reader)
+	}
+	c.mutex.Lock()
+	c.err = err
+	c.mutex.Unlock()
+}
+
+func (c *Client) receiveFrame(reader *bufio.Reader) error {
+	size, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	if size > maxFrameSize {
+		return ErrInvalidFrame
+	}
+	frame := make([]byte, size)
+	_, err = io.ReadFull(reader, frame)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return applyFrame(&c.state, frame)
+}
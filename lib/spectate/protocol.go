@@ -0,0 +1,314 @@
+// Package spectate streams a live game to read-only clients. Over TCP, the server first sends a header with
+// the protocol version, then one frame per tick. The first frame sent to a client holds the whole state;
+// each one after that only holds the parts of the state which changed since the previous tick.
+//
+// A frame is a length (uvarint) followed by the tick (uvarint), a byte flagging the sections present,
+// and the sections. Rocks are sent as a list of changed cells, other sections as a whole.
+package spectate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// magic starts the header sent to the clients, followed by the version
+const magic = "MYRIAPOD-SPECTATE"
+
+// maxFrameSize protects the client against a corrupted stream
+const maxFrameSize = 1 << 20
+
+var ErrInvalidFrame = errors.New("invalid spectator frame")
+
+// VersionError is returned when the client and server don't speak the same version of the protocol
+type VersionError struct {
+	Client int
+	Server int
+}
+
+func (e VersionError) Error() string {
+	return fmt.Sprintf("protocol version mismatch: client %d, server %d", e.Client, e.Server)
+}
+
+// Object is anything drawn on the board. The meaning of Frame and Flags is up to the game.
+type Object struct {
+	X, Y  int16
+	Frame uint8
+	Flags uint8
+}
+
+// Rock in a grid cell. An empty cell has no health.
+type Rock struct {
+	Type     uint8 // 0 to 3
+	Health   uint8 // 0 to 7
+	Totem    bool
+	Poisoned bool
+}
+
+// Score of a player
+type Score struct {
+	Score int
+	Lives int
+}
+
+// State is what a spectator sees of the game
+type State struct {
+	Tick      int
+	Wave      int
+	HighScore int
+	Over      bool
+	Scores    []Score
+	Players   []Object
+	Segments  []Object
+	Bullets   []Object
+	Enemies   []Object
+	Rocks     []Rock // all the cells of the grid, row after row
+}
+
+// Clone returns a deep copy of the state
+func (s *State) Clone() State {
+	clone := *s
+	clone.Scores = slices.Clone(s.Scores)
+	clone.Players = slices.Clone(s.Players)
+	clone.Segments = slices.Clone(s.Segments)
+	clone.Bullets = slices.Clone(s.Bullets)
+	clone.Enemies = slices.Clone(s.Enemies)
+	clone.Rocks = slices.Clone(s.Rocks)
+	return clone
+}
+
+// sections of a frame
+const (
+	sectionInfo = 1 << iota
+	sectionPlayers
+	sectionSegments
+	sectionBullets
+	sectionEnemies
+	sectionRocks
+)
+
+func (r Rock) encode() byte {
+	if r.Health == 0 {
+		return 0
+	}
+	value := r.Type&3 | (r.Health&7)<<2
+	if r.Totem {
+		value |= 1 << 5
+	}
+	if r.Poisoned {
+		value |= 1 << 6
+	}
+	return value
+}
+
+func decodeRock(value byte) Rock {
+	return Rock{
+		Type:     value & 3,
+		Health:   (value >> 2) & 7,
+		Totem:    value&(1<<5) != 0,
+		Poisoned: value&(1<<6) != 0,
+	}
+}
+
+func appendInfo(buffer []byte, state *State) []byte {
+	buffer = binary.AppendVarint(buffer, int64(state.Wave))
+	buffer = binary.AppendUvarint(buffer, uint64(state.HighScore))
+	if state.Over {
+		buffer = append(buffer, 1)
+	} else {
+		buffer = append(buffer, 0)
+	}
+	buffer = binary.AppendUvarint(buffer, uint64(len(state.Scores)))
+	for _, score := range state.Scores {
+		buffer = binary.AppendUvarint(buffer, uint64(score.Score))
+		buffer = binary.AppendUvarint(buffer, uint64(score.Lives))
+	}
+	return buffer
+}
+
+func appendObjects(buffer []byte, objects []Object) []byte {
+	buffer = binary.AppendUvarint(buffer, uint64(len(objects)))
+	for _, object := range objects {
+		buffer = binary.BigEndian.AppendUint16(buffer, uint16(object.X))
+		buffer = binary.BigEndian.AppendUint16(buffer, uint16(object.Y))
+		buffer = append(buffer, object.Frame, object.Flags)
+	}
+	return buffer
+}
+
+// appendRocks adds the cells which changed since previous. It also returns the number of cells changed.
+func appendRocks(buffer []byte, previous, rocks []Rock) ([]byte, int) {
+	if len(previous) != len(rocks) {
+		// the client starts again from an empty grid
+		previous = make([]Rock, len(rocks))
+	}
+	changed := make([]int, 0, len(rocks))
+	for i := range rocks {
+		if rocks[i].encode() != previous[i].encode() {
+			changed = append(changed, i)
+		}
+	}
+	buffer = binary.AppendUvarint(buffer, uint64(len(rocks)))
+	buffer = binary.AppendUvarint(buffer, uint64(len(changed)))
+	for _, i := range changed {
+		buffer = binary.AppendUvarint(buffer, uint64(i))
+		buffer = append(buffer, rocks[i].encode())
+	}
+	return buffer, len(changed)
+}
+
+// encodeFrame returns the frame turning previous into state
+func encodeFrame(previous, state *State) []byte {
+	var sections byte
+	body := make([]byte, 0, 256)
+	section := func(flag byte, encoded, before []byte) {
+		if !bytes.Equal(encoded, before) {
+			sections |= flag
+			body = append(body, encoded...)
+		}
+	}
+	section(sectionInfo, appendInfo(nil, state), appendInfo(nil, previous))
+	section(sectionPlayers, appendObjects(nil, state.Players), appendObjects(nil, previous.Players))
+	section(sectionSegments, appendObjects(nil, state.Segments), appendObjects(nil, previous.Segments))
+	section(sectionBullets, appendObjects(nil, state.Bullets), appendObjects(nil, previous.Bullets))
+	section(sectionEnemies, appendObjects(nil, state.Enemies), appendObjects(nil, previous.Enemies))
+	rocks, changed := appendRocks(nil, previous.Rocks, state.Rocks)
+	if changed > 0 || len(previous.Rocks) != len(state.Rocks) {
+		sections |= sectionRocks
+		body = append(body, rocks...)
+	}
+
+	frame := binary.AppendUvarint(make([]byte, 0, len(body)+8), uint64(state.Tick))
+	frame = append(frame, sections)
+	frame = append(frame, body...)
+	return append(binary.AppendUvarint(make([]byte, 0, len(frame)+4), uint64(len(frame))), frame...)
+}
+
+// reader decodes the content of a frame, remembering the first error
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) uvarint() int {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 || value > maxFrameSize*8 {
+		r.err = ErrInvalidFrame
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(value)
+}
+
+func (r *reader) varint() int {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = ErrInvalidFrame
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(value)
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = ErrInvalidFrame
+		return nil
+	}
+	value := r.data[:n]
+	r.data = r.data[n:]
+	return value
+}
+
+func (r *reader) objects() []Object {
+	count := r.uvarint()
+	if r.err != nil || count*6 > len(r.data) {
+		r.err = ErrInvalidFrame
+		return nil
+	}
+	objects := make([]Object, count)
+	for i := range objects {
+		data := r.bytes(6)
+		objects[i] = Object{
+			X:     int16(binary.BigEndian.Uint16(data)),
+			Y:     int16(binary.BigEndian.Uint16(data[2:])),
+			Frame: data[4],
+			Flags: data[5],
+		}
+	}
+	return objects
+}
+
+// applyFrame updates the state with the content of a frame (without its length)
+func applyFrame(state *State, frame []byte) error {
+	r := &reader{data: frame}
+	tick := r.uvarint()
+	sections := r.bytes(1)
+	if r.err != nil {
+		return r.err
+	}
+	next := state.Clone()
+	next.Tick = tick
+	if sections[0]&sectionInfo != 0 {
+		next.Wave = r.varint()
+		next.HighScore = r.uvarint()
+		over := r.bytes(1)
+		next.Over = over != nil && over[0] == 1
+		count := r.uvarint()
+		if r.err == nil && count > len(r.data) {
+			r.err = ErrInvalidFrame
+		}
+		next.Scores = make([]Score, 0, count)
+		for i := 0; i < count && r.err == nil; i++ {
+			next.Scores = append(next.Scores, Score{Score: r.uvarint(), Lives: r.uvarint()})
+		}
+	}
+	if sections[0]&sectionPlayers != 0 {
+		next.Players = r.objects()
+	}
+	if sections[0]&sectionSegments != 0 {
+		next.Segments = r.objects()
+	}
+	if sections[0]&sectionBullets != 0 {
+		next.Bullets = r.objects()
+	}
+	if sections[0]&sectionEnemies != 0 {
+		next.Enemies = r.objects()
+	}
+	if sections[0]&sectionRocks != 0 {
+		size := r.uvarint()
+		if len(next.Rocks) != size {
+			next.Rocks = make([]Rock, size)
+		}
+		count := r.uvarint()
+		for i := 0; i < count && r.err == nil; i++ {
+			cell := r.uvarint()
+			value := r.bytes(1)
+			if r.err == nil && cell >= size {
+				r.err = ErrInvalidFrame
+			}
+			if r.err == nil {
+				next.Rocks[cell] = decodeRock(value[0])
+			}
+		}
+	}
+	if r.err == nil && len(r.data) > 0 {
+		r.err = ErrInvalidFrame
+	}
+	if r.err != nil {
+		return r.err
+	}
+	*state = next
+	return nil
+}
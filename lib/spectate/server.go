@@ -0,0 +1,141 @@
+package spectate
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// Timeout to connect, and to send a frame to a client before dropping it
+	Timeout = 5 * time.Second
+	// queueSize is the number of frames waiting to be sent to a client before it's considered too slow and dropped
+	queueSize = 120
+)
+
+type client struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+// Server streams the state of a game to all the connected clients
+type Server struct {
+	listener net.Listener
+	version  int
+	mutex    sync.Mutex
+	state    State // last state sent
+	clients  map[*client]struct{}
+}
+
+// Listen for spectators on the address (host:port)
+func Listen(addr string, version int) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		listener: listener,
+		version:  version,
+		clients:  make(map[*client]struct{}),
+	}, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve accepts spectators until the server is closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		s.add(conn)
+	}
+}
+
+// Close the server and disconnect all the clients
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for c := range s.clients {
+		s.remove(c)
+	}
+	return err
+}
+
+// Clients returns the number of connected clients
+func (s *Server) Clients() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+// Send the state of the game to all the clients, as the changes since the last state sent
+func (s *Server) Send(state State) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	frame := encodeFrame(&s.state, &state)
+	s.state = state.Clone()
+	for c := range s.clients {
+		select {
+		case c.frames <- frame:
+		default:
+			log.Printf("spectator %s is too slow: disconnected", c.conn.RemoteAddr())
+			s.remove(c)
+		}
+	}
+}
+
+// add a client: it starts with the whole state
+func (s *Server) add(conn net.Conn) {
+	c := &client{
+		conn:   conn,
+		frames: make(chan []byte, queueSize),
+	}
+	s.mutex.Lock()
+	c.frames <- encodeFrame(&State{}, &s.state)
+	s.clients[c] = struct{}{}
+	s.mutex.Unlock()
+	go s.write(c)
+}
+
+// remove a client. The mutex must be held.
+func (s *Server) remove(c *client) {
+	if _, found := s.clients[c]; !found {
+		return
+	}
+	delete(s.clients, c)
+	close(c.frames)
+	c.conn.Close()
+}
+
+func (s *Server) write(c *client) {
+	header := append([]byte(magic), byte(s.version))
+	frames := [][]byte{header}
+	for {
+		for _, frame := range frames {
+			c.conn.SetWriteDeadline(time.Now().Add(Timeout))
+			_, err := c.conn.Write(frame)
+			if err != nil {
+				s.mutex.Lock()
+				s.remove(c)
+				s.mutex.Unlock()
+				return
+			}
+		}
+		frame, ok := <-c.frames
+		if !ok {
+			return
+		}
+		frames = [][]byte{frame}
+	}
+}
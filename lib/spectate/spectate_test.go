@@ -0,0 +1,150 @@
+package spectate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testState(tick int) State {
+	state := State{
+		Tick:      tick,
+		Wave:      2,
+		HighScore: 12000,
+		Scores:    []Score{{Score: 1230, Lives: 2}},
+		Players:   []Object{{X: 240, Y: 768, Frame: 3}},
+		Segments:  []Object{{X: 100, Y: 32, Frame: 0x25, Flags: 1}, {X: 68, Y: 32, Frame: 0x05}},
+		Bullets:   []Object{{X: 240, Y: 600}},
+		Enemies:   []Object{{X: -35, Y: 688, Frame: 1, Flags: 2}},
+		Rocks:     make([]Rock, 15*25),
+	}
+	state.Rocks[20] = Rock{Type: 2, Health: 3}
+	state.Rocks[31] = Rock{Type: 1, Health: 5, Totem: true}
+	state.Rocks[200] = Rock{Type: 3, Health: 1, Poisoned: true}
+	return state
+}
+
+// decode a frame including its length
+func decode(t *testing.T, state *State, frame []byte) {
+	t.Helper()
+	// the lengths of the frames in the tests fit in 2 bytes
+	header := 1
+	if frame[0]&0x80 != 0 {
+		header = 2
+	}
+	require.NoError(t, applyFrame(state, frame[header:]))
+}
+
+func TestFullFrame(t *testing.T) {
+	expected := testState(10)
+	state := State{}
+	decode(t, &state, encodeFrame(&State{}, &expected))
+	assert.Equal(t, expected, state)
+}
+
+func TestDeltaFrames(t *testing.T) {
+	previous := testState(10)
+	state := previous.Clone()
+	decode(t, &state, encodeFrame(&State{}, &previous))
+
+	// nothing changed but the tick
+	next := previous.Clone()
+	next.Tick = 11
+	frame := encodeFrame(&previous, &next)
+	assert.Len(t, frame, 3)
+	decode(t, &state, frame)
+	assert.Equal(t, next, state)
+
+	// a rock is destroyed, the segments moved and the score changed
+	previous, next = next, next.Clone()
+	next.Tick = 12
+	next.Rocks[20] = Rock{}
+	next.Segments[0].X += 2
+	next.Segments[1].X += 2
+	next.Scores[0].Score += 10
+	next.Over = true
+	frame = encodeFrame(&previous, &next)
+	assert.Less(t, len(frame), len(encodeFrame(&State{}, &next)))
+	decode(t, &state, frame)
+	assert.Equal(t, next, state)
+
+	// all the segments and bullets gone
+	previous, next = next, next.Clone()
+	next.Tick = 13
+	next.Segments = []Object{}
+	next.Bullets = []Object{}
+	decode(t, &state, encodeFrame(&previous, &next))
+	assert.Equal(t, next, state)
+}
+
+func TestInvalidFrame(t *testing.T) {
+	full := testState(1)
+	frame := encodeFrame(&State{}, &full)[2:]
+	for _, data := range [][]byte{{}, {1}, frame[:len(frame)-1], append(frame, 0)} {
+		state := testState(0)
+		assert.ErrorIs(t, applyFrame(&state, data), ErrInvalidFrame)
+		// the state is left as it was
+		assert.Equal(t, testState(0), state)
+	}
+}
+
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	server, err := Listen("127.0.0.1:0", 1)
+	require.NoError(t, err)
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func waitForTick(t *testing.T, client *Client, tick int) State {
+	t.Helper()
+	var state State
+	require.Eventually(t, func() bool {
+		state, _ = client.State()
+		return state.Tick == tick
+	}, time.Second, time.Millisecond)
+	return state
+}
+
+func TestStream(t *testing.T) {
+	server := startServer(t)
+	server.Send(testState(1))
+
+	client1, err := Dial(server.Addr().String(), 1)
+	require.NoError(t, err)
+	defer client1.Close()
+	assert.Equal(t, testState(1), waitForTick(t, client1, 1))
+
+	for tick := 2; tick <= 30; tick++ {
+		state := testState(tick)
+		state.Segments[0].X += int16(tick)
+		state.Rocks[tick] = Rock{Type: 1, Health: 4}
+		server.Send(state)
+	}
+	expected := testState(30)
+	expected.Segments[0].X += 30
+	expected.Rocks[30] = Rock{Type: 1, Health: 4}
+	assert.Equal(t, expected, waitForTick(t, client1, 30))
+
+	// a late spectator starts from the whole state
+	client2, err := Dial(server.Addr().String(), 1)
+	require.NoError(t, err)
+	defer client2.Close()
+	assert.Equal(t, expected, waitForTick(t, client2, 30))
+	assert.Equal(t, 2, server.Clients())
+
+	server.Close()
+	require.Eventually(t, func() bool {
+		_, err := client1.State()
+		return err != nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestVersionMismatch(t *testing.T) {
+	server := startServer(t)
+	_, err := Dial(server.Addr().String(), 2)
+	assert.Equal(t, VersionError{Client: 2, Server: 1}, err)
+}
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"image/color"
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -26,6 +27,19 @@ type Sprite struct {
 	rate         int             // change image every n frame per second
 	loop         bool            // animation loop
 	started      bool            // is animation running?
+	animations   *AnimationSet   // clips available to Play
+	clip         *Clip           // clip currently playing (clip mode)
+	clipFrame    int             // current frame in the clip
+	clipTimer    int             // ticks spent on the current frame
+	clipStep     int             // 1 when playing forward, -1 when playing backward (ping-pong)
+	eventFunc    EventFunc       // called when reaching a frame with an event
+	rotation     float64         // rotation around the anchor, in radians (clockwise)
+	scaleX       float64         // horizontal scale
+	scaleY       float64         // vertical scale
+	flipX        bool            // mirror the image horizontally
+	flipY        bool            // mirror the image vertically
+	tint         color.Color     // colour multiplied with the image (nil for none)
+	alpha        float64         // opacity between 0 and 1
 	op           *ebiten.DrawImageOptions
 }
 
@@ -36,6 +50,9 @@ func NewSprite(xType XType, yType YType) *Sprite {
 		yType:   yType,
 		op:      &ebiten.DrawImageOptions{},
 		started: false,
+		scaleX:  1,
+		scaleY:  1,
+		alpha:   1,
 	}
 }
 
@@ -61,11 +78,62 @@ func (s *Sprite) SetSize(width, height int) *Sprite {
 	return s
 }
 
+// SetRotation sets the rotation of the image around the sprite coordinates, in radians (clockwise)
+func (s *Sprite) SetRotation(angle float64) *Sprite {
+	s.rotation = angle
+	return s
+}
+
+// Rotation returns the rotation of the image in radians
+func (s *Sprite) Rotation() float64 {
+	return s.rotation
+}
+
+// SetScale sets the horizontal and vertical scale of the image. The image is scaled around the sprite coordinates.
+func (s *Sprite) SetScale(scaleX, scaleY float64) *Sprite {
+	s.scaleX = scaleX
+	s.scaleY = scaleY
+	return s
+}
+
+// Scale returns the horizontal and vertical scale of the image
+func (s *Sprite) Scale() (float64, float64) {
+	return s.scaleX, s.scaleY
+}
+
+// SetFlip mirrors the image horizontally and/or vertically. The image stays in place.
+func (s *Sprite) SetFlip(flipX, flipY bool) *Sprite {
+	s.flipX = flipX
+	s.flipY = flipY
+	return s
+}
+
+// SetTint sets a colour multiplied with the image. Use nil to remove the tint.
+func (s *Sprite) SetTint(tint color.Color) *Sprite {
+	s.tint = tint
+	return s
+}
+
+// SetAlpha sets the opacity of the image, from 0 (invisible) to 1 (opaque)
+func (s *Sprite) SetAlpha(alpha float64) *Sprite {
+	s.alpha = math.Max(0, math.Min(1, alpha))
+	return s
+}
+
+// Alpha returns the opacity of the image
+func (s *Sprite) Alpha() float64 {
+	return s.alpha
+}
+
 // Update animation (if needed)
 func (s *Sprite) Update() {
 	if !s.started {
 		return
 	}
+	if s.clip != nil {
+		s.updateClip()
+		return
+	}
 	s.frame++
 	if !s.loop && s.frame >= s.rate*len(s.animation) {
 		// animation is finished
@@ -87,14 +155,49 @@ func (s *Sprite) Draw(screen *ebiten.Image) {
 		log.Print("Sprite.Draw: no image to draw")
 		return
 	}
-	width, height := s.image.Size()
+	if s.alpha <= 0 {
+		return
+	}
+	imageWidth, imageHeight := s.image.Size()
+	width, height := float64(imageWidth), float64(imageHeight)
 	s.op.GeoM.Reset()
-	s.op.GeoM.Translate(s.xleft(float64(width)), s.ytop(float64(height)))
+	if s.flipX || s.flipY {
+		// mirror around the centre of the image so it doesn't move
+		flipX, flipY := 1.0, 1.0
+		if s.flipX {
+			flipX = -1
+		}
+		if s.flipY {
+			flipY = -1
+		}
+		s.op.GeoM.Translate(-width/2, -height/2)
+		s.op.GeoM.Scale(flipX, flipY)
+		s.op.GeoM.Translate(width/2, height/2)
+	}
+	// move the anchor to the origin, then scale and rotate around it
+	s.op.GeoM.Translate(-s.xType.offset(width), -s.yType.offset(height))
+	s.op.GeoM.Scale(s.scaleX, s.scaleY)
+	s.op.GeoM.Rotate(s.rotation)
+	s.op.GeoM.Translate(s.x, s.y)
+
+	s.op.ColorScale.Reset()
+	if s.tint != nil {
+		s.op.ColorScale.ScaleWithColor(s.tint)
+	}
+	if s.alpha < 1 {
+		s.op.ColorScale.ScaleAlpha(float32(s.alpha))
+	}
 	screen.DrawImage(s.image, s.op)
 }
 
 // Start (or restart) an animation
 func (s *Sprite) Start() *Sprite {
+	if s.clip != nil {
+		s.clipStep = 1
+		s.setClipFrame(0)
+		s.started = true
+		return s
+	}
 	// only start if the animation is well defined
 	if s.animation != nil && len(s.animation) > 0 && s.rate > 0 {
 		s.frame = 0
@@ -111,6 +214,7 @@ func (s *Sprite) Stop() *Sprite {
 
 // Animation defines a new animation (but does not start it yet)
 func (s *Sprite) Animation(animation []*ebiten.Image, sequence []int, rate int, loop bool) *Sprite {
+	s.clip = nil
 	s.animation = animation
 	s.sequence = sequence
 	s.rate = rate
@@ -134,6 +238,41 @@ func (s *Sprite) SetSequenceFunc(sequenceFunc SequenceFunc) *Sprite {
 	return s
 }
 
+// SetAnimations registers the set of clips available to Play
+func (s *Sprite) SetAnimations(animations *AnimationSet) *Sprite {
+	s.animations = animations
+	return s
+}
+
+// Play starts the clip by its name, from the animation set registered with SetAnimations
+func (s *Sprite) Play(name string) *Sprite {
+	if s.animations == nil {
+		log.Print("Sprite.Play: no animation set")
+		return s
+	}
+	clip := s.animations.Clip(name)
+	if clip == nil {
+		log.Print("Sprite.Play: no clip named " + name)
+		return s
+	}
+	s.clip = clip
+	return s.Start()
+}
+
+// ClipName returns the name of the clip currently set, or an empty string
+func (s *Sprite) ClipName() string {
+	if s.clip == nil {
+		return ""
+	}
+	return s.clip.Name
+}
+
+// SetEventFunc registers a callback receiving the events attached to the clip frames
+func (s *Sprite) SetEventFunc(eventFunc EventFunc) *Sprite {
+	s.eventFunc = eventFunc
+	return s
+}
+
 // IsFinished returns true when the *Sprite animation has finished.
 // An animation with loop = true will never finish
 func (s *Sprite) IsFinished() bool {
@@ -154,21 +293,13 @@ func (s *Sprite) MoveTo(x, y float64) *Sprite {
 	return s
 }
 
-// MoveToType moves to the new coordinates using the specified coordinate types
+// MoveToType moves to the new coordinates using the specified coordinate types.
+// The coordinates are converted to the types defined at instantiation using the size of the (scaled) image.
+// If no image or size is set yet, the sprite is considered as a single point.
 func (s *Sprite) MoveToType(x, y float64, xType XType, yType YType) *Sprite {
-	if s.xType == xType {
-		s.x = x
-	} else {
-		panic(fmt.Sprintf("mixing different types of coordinates is not yet supported: want to set %s but is %s", xType.String(), s.xType.String()))
-	}
-	if s.yType == yType {
-		s.y = y
-	} else if s.yType == YCentre && yType == YBottom {
-		_, height := s.image.Size()
-		s.y = y - (float64(height) / 2)
-	} else {
-		panic(fmt.Sprintf("mixing different types of coordinates is not yet supported: want to set %s but is %s", yType.String(), s.yType.String()))
-	}
+	width, height := s.size()
+	s.x = x - xType.offset(width) + s.xType.offset(width)
+	s.y = y - yType.offset(height) + s.yType.offset(height)
 	return s
 }
 
@@ -182,44 +313,74 @@ func (s *Sprite) RawY() float64 {
 	return s.y
 }
 
-// X returns x position. If no image is available to calculate width, it returns -1
+// X returns x position of the scaled image (rotation is ignored).
+// If no image or size is set yet, all the coordinate types return the same position.
 func (s *Sprite) X(xType XType) float64 {
-	if s.image == nil {
-		return -1
-	}
-	width, _ := s.image.Size()
-	switch xType {
-	case XCentre:
-		return s.xcentre(float64(width))
-	case XRight:
-		return s.xright(float64(width))
-	default:
-		return s.xleft(float64(width))
-	}
+	width, _ := s.size()
+	return s.x - s.xType.offset(width) + xType.offset(width)
 }
 
-// Y returns y position. If no image is available to calculate height, it returns -1
+// Y returns y position of the scaled image (rotation is ignored).
+// If no image or size is set yet, all the coordinate types return the same position.
 func (s *Sprite) Y(yType YType) float64 {
-	if s.image == nil {
-		return -1
-	}
-	_, height := s.image.Size()
-	switch yType {
-	case YCentre:
-		return s.ycentre(float64(height))
-	case YBottom:
-		return s.ybottom(float64(height))
-	default:
-		return s.ytop(float64(height))
-	}
+	_, height := s.size()
+	return s.y - s.yType.offset(height) + yType.offset(height)
 }
 
-// CollidePoint returns true when the coordinates are "touching" the sprite
+// CollidePoint returns true when the coordinates are "touching" the sprite (scaled and rotated)
 func (s *Sprite) CollidePoint(x, y float64) bool {
+	if s.rotation != 0 {
+		// rotate the point the other way around the anchor, so we can compare it with the unrotated image
+		sin, cos := math.Sincos(-s.rotation)
+		dx, dy := x-s.x, y-s.y
+		x = s.x + dx*cos - dy*sin
+		y = s.y + dx*sin + dy*cos
+	}
 	return s.X(XLeft) <= x && x <= s.X(XRight) &&
 		s.Y(YTop) <= y && y <= s.Y(YBottom)
 }
 
+// size returns the size of the scaled image, or the size forced by SetSize
+func (s *Sprite) size() (float64, float64) {
+	width, height := s.width, s.height
+	if width == 0 && height == 0 && s.image != nil {
+		width, height = s.image.Size()
+	}
+	return float64(width) * math.Abs(s.scaleX), float64(height) * math.Abs(s.scaleY)
+}
+
+func (s *Sprite) updateClip() {
+	s.clipTimer++
+	if s.clipTimer < s.clip.Durations[s.clipFrame] {
+		return
+	}
+	next := s.clipFrame + s.clipStep
+	if next < 0 || next >= s.clip.Length() {
+		switch s.clip.Loop {
+		case LoopForever:
+			next = 0
+		case LoopPingPong:
+			s.clipStep = -s.clipStep
+			// a single frame clip stays on its frame
+			next = min(max(s.clipFrame+s.clipStep, 0), s.clip.Length()-1)
+		default:
+			// animation is finished: we stay on the last frame
+			s.started = false
+			return
+		}
+	}
+	s.setClipFrame(next)
+}
+
+func (s *Sprite) setClipFrame(frame int) {
+	s.clipFrame = frame
+	s.clipTimer = 0
+	s.image = s.clip.Frames[frame]
+	if event, found := s.clip.Events[frame]; found && s.eventFunc != nil {
+		s.eventFunc(event)
+	}
+}
+
 func (s *Sprite) getFrameID() int {
 	if s.sequenceFunc != nil {
 		return s.sequenceFunc(s.frame)
@@ -237,69 +398,3 @@ func (s *Sprite) getFrameID() int {
 	}
 	return frameID
 }
-
-func (s *Sprite) xleft(width float64) float64 {
-	switch s.xType {
-	case XCentre:
-		return s.x - (width / 2)
-	case XRight:
-		return s.x - width
-	default:
-		return s.x
-	}
-}
-
-func (s *Sprite) xcentre(width float64) float64 {
-	switch s.xType {
-	case XCentre:
-		return s.x
-	case XRight:
-		return s.x - (width / 2)
-	default:
-		return s.x + (width / 2)
-	}
-}
-
-func (s *Sprite) xright(width float64) float64 {
-	switch s.xType {
-	case XCentre:
-		return s.x + (width / 2)
-	case XRight:
-		return s.x
-	default:
-		return s.x + width
-	}
-}
-
-func (s *Sprite) ytop(height float64) float64 {
-	switch s.yType {
-	case YCentre:
-		return s.y - (height / 2)
-	case YBottom:
-		return s.y - height
-	default:
-		return s.y
-	}
-}
-
-func (s *Sprite) ycentre(height float64) float64 {
-	switch s.yType {
-	case YCentre:
-		return s.y
-	case YBottom:
-		return s.y - (height / 2)
-	default:
-		return s.y + (height / 2)
-	}
-}
-
-func (s *Sprite) ybottom(height float64) float64 {
-	switch s.yType {
-	case YCentre:
-		return s.y + (height / 2)
-	case YBottom:
-		return s.y
-	default:
-		return s.y + height
-	}
-}
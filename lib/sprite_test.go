@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaledCoordinates(t *testing.T) {
+	testCases := []struct {
+		xType          XType
+		yType          YType
+		scaleX, scaleY float64
+		left, right    float64
+		top, bottom    float64
+	}{
+		{XCentre, YCentre, 1, 1, 90, 110, 95, 105},
+		{XCentre, YCentre, 2, 2, 80, 120, 90, 110},
+		{XCentre, YCentre, -2, 0.5, 80, 120, 97.5, 102.5},
+		{XLeft, YTop, 2, 3, 100, 140, 100, 130},
+		{XRight, YBottom, 0.5, 2, 90, 100, 80, 100},
+	}
+	for _, testCase := range testCases {
+		sprite := NewSprite(testCase.xType, testCase.yType).
+			SetImage(ebiten.NewImage(20, 10)).
+			MoveTo(100, 100).
+			SetScale(testCase.scaleX, testCase.scaleY)
+		assert.Equal(t, testCase.left, sprite.X(XLeft))
+		assert.Equal(t, testCase.right, sprite.X(XRight))
+		assert.Equal(t, testCase.top, sprite.Y(YTop))
+		assert.Equal(t, testCase.bottom, sprite.Y(YBottom))
+	}
+}
+
+func TestRotatedCollision(t *testing.T) {
+	sprite := NewSprite(XCentre, YCentre).SetImage(ebiten.NewImage(40, 10)).MoveTo(100, 100)
+	assert.True(t, sprite.CollidePoint(115, 100))
+	assert.False(t, sprite.CollidePoint(100, 115))
+
+	sprite.SetRotation(math.Pi / 2)
+	assert.False(t, sprite.CollidePoint(115, 100))
+	assert.True(t, sprite.CollidePoint(100, 115))
+	assert.True(t, sprite.CollidePoint(100, 85))
+
+	// rotating around the top left corner
+	sprite = NewSprite(XLeft, YTop).SetImage(ebiten.NewImage(40, 10)).MoveTo(100, 100).SetRotation(math.Pi / 2)
+	assert.True(t, sprite.CollidePoint(95, 130))
+	assert.False(t, sprite.CollidePoint(105, 130))
+}
+
+func TestAlpha(t *testing.T) {
+	sprite := NewSprite(XCentre, YCentre)
+	assert.Equal(t, 1.0, sprite.Alpha())
+	assert.Equal(t, 0.0, sprite.SetAlpha(-1).Alpha())
+	assert.Equal(t, 1.0, sprite.SetAlpha(2).Alpha())
+	assert.Equal(t, 0.5, sprite.SetAlpha(0.5).Alpha())
+}
+
+func TestMoveToType(t *testing.T) {
+	xTypes := []XType{XLeft, XCentre, XRight}
+	yTypes := []YType{YTop, YCentre, YBottom}
+	// expected position of each coordinate type for a 20x10 image with its top left corner at (100, 200)
+	expectedX := map[XType]float64{XLeft: 100, XCentre: 110, XRight: 120}
+	expectedY := map[YType]float64{YTop: 200, YCentre: 205, YBottom: 210}
+
+	for _, spriteXType := range xTypes {
+		for _, spriteYType := range yTypes {
+			for _, xType := range xTypes {
+				for _, yType := range yTypes {
+					t.Run(spriteXType.String()+","+spriteYType.String()+" from "+xType.String()+","+yType.String(), func(t *testing.T) {
+						sprite := NewSprite(spriteXType, spriteYType).
+							SetImage(ebiten.NewImage(20, 10)).
+							MoveToType(expectedX[xType], expectedY[yType], xType, yType)
+
+						assert.Equal(t, expectedX[spriteXType], sprite.RawX())
+						assert.Equal(t, expectedY[spriteYType], sprite.RawY())
+						for _, otherXType := range xTypes {
+							assert.Equal(t, expectedX[otherXType], sprite.X(otherXType))
+						}
+						for _, otherYType := range yTypes {
+							assert.Equal(t, expectedY[otherYType], sprite.Y(otherYType))
+						}
+						assert.True(t, sprite.CollidePoint(expectedX[xType], expectedY[yType]))
+						assert.False(t, sprite.CollidePoint(expectedX[XRight]+1, expectedY[yType]))
+						assert.False(t, sprite.CollidePoint(expectedX[xType], expectedY[YTop]-1))
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestCoordinatesWithoutImage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height int
+		left, centre  float64
+		top, bottom   float64
+	}{
+		{"no image", 0, 0, 50, 50, 60, 60},
+		{"fixed size", 20, 10, 40, 50, 55, 65},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sprite := NewSprite(XCentre, YCentre).SetSize(testCase.width, testCase.height)
+			assert.NotPanics(t, func() {
+				sprite.MoveToType(testCase.left, testCase.bottom, XLeft, YBottom)
+			})
+			assert.Equal(t, testCase.left, sprite.X(XLeft))
+			assert.Equal(t, testCase.centre, sprite.X(XCentre))
+			assert.Equal(t, testCase.top, sprite.Y(YTop))
+			assert.Equal(t, testCase.bottom, sprite.Y(YBottom))
+			assert.True(t, sprite.CollidePoint(testCase.centre, testCase.top))
+		})
+	}
+}
+
+func TestMoveToTypeScaled(t *testing.T) {
+	sprite := NewSprite(XCentre, YBottom).SetImage(ebiten.NewImage(20, 10)).SetScale(2, 2).MoveToType(0, 0, XLeft, YTop)
+	assert.Equal(t, 20.0, sprite.RawX())
+	assert.Equal(t, 20.0, sprite.RawY())
+	assert.Equal(t, 40.0, sprite.X(XRight))
+}
@@ -0,0 +1,99 @@
+package tween
+
+import "math"
+
+// EasingFunc maps the progress of a tween (between 0 and 1) to the progress of the value
+type EasingFunc func(t float64) float64
+
+// Linear keeps a constant speed
+func Linear(t float64) float64 {
+	return t
+}
+
+// InQuad starts slowly and accelerates
+func InQuad(t float64) float64 {
+	return t * t
+}
+
+// OutQuad starts fast and decelerates
+func OutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+// InOutQuad accelerates then decelerates
+func InOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// InCubic starts slowly and accelerates (stronger than InQuad)
+func InCubic(t float64) float64 {
+	return t * t * t
+}
+
+// OutCubic starts fast and decelerates (stronger than OutQuad)
+func OutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// InOutCubic accelerates then decelerates (stronger than InOutQuad)
+func InOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// InSine starts slowly following a sine curve
+func InSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+// OutSine decelerates following a sine curve
+func OutSine(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+// InOutSine accelerates then decelerates following a sine curve
+func InOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// OutBack overshoots the target a little before settling
+func OutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	t--
+	return 1 + c3*t*t*t + c1*t*t
+}
+
+// OutElastic overshoots the target and oscillates around it
+func OutElastic(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*(2*math.Pi/3)) + 1
+}
+
+// OutBounce bounces on the target like a falling ball
+func OutBounce(t float64) float64 {
+	const n1 = 7.5625
+	const d1 = 2.75
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
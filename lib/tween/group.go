@@ -0,0 +1,141 @@
+package tween
+
+// Sequence runs tweens one after another
+type Sequence struct {
+	tweens     []Tweener
+	current    int
+	onComplete func()
+}
+
+// NewSequence creates a sequence of tweens
+func NewSequence(tweens ...Tweener) *Sequence {
+	return &Sequence{
+		tweens: tweens,
+	}
+}
+
+// OnComplete registers a callback when the last tween of the sequence is finished
+func (s *Sequence) OnComplete(onComplete func()) *Sequence {
+	s.onComplete = onComplete
+	return s
+}
+
+// Update moves the current tween forward by one tick
+func (s *Sequence) Update() bool {
+	if s.Done() {
+		return true
+	}
+	if s.tweens[s.current].Update() {
+		s.current++
+		if s.Done() && s.onComplete != nil {
+			s.onComplete()
+		}
+	}
+	return s.Done()
+}
+
+// Done returns true when all the tweens are finished
+func (s *Sequence) Done() bool {
+	return s.current >= len(s.tweens)
+}
+
+// Reset all the tweens of the sequence
+func (s *Sequence) Reset() {
+	s.current = 0
+	for _, tween := range s.tweens {
+		tween.Reset()
+	}
+}
+
+// Parallel runs tweens at the same time
+type Parallel struct {
+	tweens     []Tweener
+	done       bool
+	onComplete func()
+}
+
+// NewParallel creates a group of tweens running at the same time
+func NewParallel(tweens ...Tweener) *Parallel {
+	return &Parallel{
+		tweens: tweens,
+	}
+}
+
+// OnComplete registers a callback when all the tweens are finished
+func (p *Parallel) OnComplete(onComplete func()) *Parallel {
+	p.onComplete = onComplete
+	return p
+}
+
+// Update moves all the tweens forward by one tick
+func (p *Parallel) Update() bool {
+	if p.done {
+		return true
+	}
+	done := true
+	for _, tween := range p.tweens {
+		if !tween.Update() {
+			done = false
+		}
+	}
+	if done {
+		p.done = true
+		if p.onComplete != nil {
+			p.onComplete()
+		}
+	}
+	return done
+}
+
+// Done returns true when all the tweens are finished
+func (p *Parallel) Done() bool {
+	return p.done
+}
+
+// Reset all the tweens of the group
+func (p *Parallel) Reset() {
+	p.done = false
+	for _, tween := range p.tweens {
+		tween.Reset()
+	}
+}
+
+// Repeat runs a tween a number of times. A negative count repeats forever.
+type Repeat struct {
+	tween Tweener
+	count int
+	runs  int
+}
+
+// NewRepeat creates a tween repeating another one count times (or forever when count is negative)
+func NewRepeat(tween Tweener, count int) *Repeat {
+	return &Repeat{
+		tween: tween,
+		count: count,
+	}
+}
+
+// Update moves the tween forward by one tick, and restarts it when finished
+func (r *Repeat) Update() bool {
+	if r.Done() {
+		return true
+	}
+	if r.tween.Update() {
+		r.runs++
+		if !r.Done() {
+			r.tween.Reset()
+		}
+	}
+	return r.Done()
+}
+
+// Done returns true when the tween has run count times (never when repeating forever)
+func (r *Repeat) Done() bool {
+	return r.count >= 0 && r.runs >= r.count
+}
+
+// Reset the tween and the count of runs
+func (r *Repeat) Reset() {
+	r.runs = 0
+	r.tween.Reset()
+}
@@ -0,0 +1,49 @@
+package tween
+
+import "github.com/cavern/creativeprojects/myriapod/lib"
+
+// MoveTo moves the sprite from its position when the tween starts, to x, y (in the sprite coordinate types)
+func MoveTo(sprite *lib.Sprite, x, y float64, duration int, easing EasingFunc) *Parallel {
+	return NewParallel(
+		New(0, x, duration, easing, func(value float64) {
+			sprite.MoveTo(value, sprite.RawY())
+		}).FromFunc(sprite.RawX),
+		New(0, y, duration, easing, func(value float64) {
+			sprite.MoveTo(sprite.RawX(), value)
+		}).FromFunc(sprite.RawY),
+	)
+}
+
+// ScaleTo scales the sprite from its scale when the tween starts, to scaleX, scaleY
+func ScaleTo(sprite *lib.Sprite, scaleX, scaleY float64, duration int, easing EasingFunc) *Parallel {
+	return NewParallel(
+		New(0, scaleX, duration, easing, func(value float64) {
+			_, current := sprite.Scale()
+			sprite.SetScale(value, current)
+		}).FromFunc(func() float64 {
+			current, _ := sprite.Scale()
+			return current
+		}),
+		New(0, scaleY, duration, easing, func(value float64) {
+			current, _ := sprite.Scale()
+			sprite.SetScale(current, value)
+		}).FromFunc(func() float64 {
+			_, current := sprite.Scale()
+			return current
+		}),
+	)
+}
+
+// FadeTo changes the opacity of the sprite from its value when the tween starts, to alpha
+func FadeTo(sprite *lib.Sprite, alpha float64, duration int, easing EasingFunc) *Tween {
+	return New(0, alpha, duration, easing, func(value float64) {
+		sprite.SetAlpha(value)
+	}).FromFunc(sprite.Alpha)
+}
+
+// RotateTo rotates the sprite from its rotation when the tween starts, to angle (in radians)
+func RotateTo(sprite *lib.Sprite, angle float64, duration int, easing EasingFunc) *Tween {
+	return New(0, angle, duration, easing, func(value float64) {
+		sprite.SetRotation(value)
+	}).FromFunc(sprite.Rotation)
+}
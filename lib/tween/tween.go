@@ -0,0 +1,104 @@
+// Package tween animates values over a number of ticks, using easing curves.
+// Tweens can be chained in sequences or run in parallel, and are updated once per tick by the game loop.
+package tween
+
+// Tweener is anything which progresses once per tick
+type Tweener interface {
+	// Update moves the tween forward by one tick. It returns true when the tween is finished.
+	Update() bool
+	// Done returns true when the tween is finished
+	Done() bool
+	// Reset the tween to its starting point
+	Reset()
+}
+
+// Tween changes a value from one number to another in a number of ticks
+type Tween struct {
+	from       float64
+	to         float64
+	fromFunc   func() float64
+	duration   int
+	elapsed    int
+	started    bool
+	easing     EasingFunc
+	set        func(value float64)
+	onComplete func()
+}
+
+// New creates a tween changing the value from "from" to "to" over duration ticks.
+// set receives the new value at each tick. A nil easing is linear.
+func New(from, to float64, duration int, easing EasingFunc, set func(value float64)) *Tween {
+	if easing == nil {
+		easing = Linear
+	}
+	return &Tween{
+		from:     from,
+		to:       to,
+		duration: duration,
+		easing:   easing,
+		set:      set,
+	}
+}
+
+// FromFunc reads the starting value when the tween starts (instead of when it's created).
+// This is useful in a sequence, when the value is changed by a previous tween.
+func (t *Tween) FromFunc(fromFunc func() float64) *Tween {
+	t.fromFunc = fromFunc
+	return t
+}
+
+// OnComplete registers a callback when the tween is finished
+func (t *Tween) OnComplete(onComplete func()) *Tween {
+	t.onComplete = onComplete
+	return t
+}
+
+// Value returns the current value
+func (t *Tween) Value() float64 {
+	if t.duration <= 0 {
+		return t.to
+	}
+	return t.from + (t.to-t.from)*t.easing(float64(t.elapsed)/float64(t.duration))
+}
+
+// Update moves the tween forward by one tick
+func (t *Tween) Update() bool {
+	if t.Done() {
+		return true
+	}
+	if !t.started {
+		t.started = true
+		if t.fromFunc != nil {
+			t.from = t.fromFunc()
+		}
+	}
+	t.elapsed++
+	if t.set != nil {
+		t.set(t.Value())
+	}
+	if t.Done() && t.onComplete != nil {
+		t.onComplete()
+	}
+	return t.Done()
+}
+
+// Done returns true when the tween is finished
+func (t *Tween) Done() bool {
+	return t.elapsed >= t.duration
+}
+
+// Reset the tween to its starting point
+func (t *Tween) Reset() {
+	t.elapsed = 0
+	t.started = false
+}
+
+// Wait creates a tween doing nothing for a number of ticks
+func Wait(duration int) *Tween {
+	return New(0, 0, duration, nil, nil)
+}
+
+// Call creates a tween calling a function once, and finishing straight away
+func Call(callback func()) *Tween {
+	return New(0, 0, 1, nil, nil).OnComplete(callback)
+}
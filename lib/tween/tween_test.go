@@ -0,0 +1,113 @@
+package tween
+
+import (
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasingBounds(t *testing.T) {
+	easings := map[string]EasingFunc{
+		"Linear": Linear, "InQuad": InQuad, "OutQuad": OutQuad, "InOutQuad": InOutQuad,
+		"InCubic": InCubic, "OutCubic": OutCubic, "InOutCubic": InOutCubic,
+		"InSine": InSine, "OutSine": OutSine, "InOutSine": InOutSine,
+		"OutBack": OutBack, "OutElastic": OutElastic, "OutBounce": OutBounce,
+	}
+	for name, easing := range easings {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, 0, easing(0), 1e-9)
+			assert.InDelta(t, 1, easing(1), 1e-9)
+		})
+	}
+}
+
+func TestTween(t *testing.T) {
+	values := []float64{}
+	completed := false
+	tween := New(10, 20, 4, Linear, func(value float64) {
+		values = append(values, value)
+	}).OnComplete(func() {
+		completed = true
+	})
+	for i := 0; i < 6; i++ {
+		tween.Update()
+	}
+	assert.Equal(t, []float64{12.5, 15, 17.5, 20}, values)
+	assert.True(t, completed)
+	assert.True(t, tween.Done())
+
+	tween.Reset()
+	assert.False(t, tween.Done())
+	assert.Equal(t, 10.0, tween.Value())
+}
+
+func TestSequence(t *testing.T) {
+	value := 0.0
+	set := func(v float64) { value = v }
+	calls := 0
+	sequence := NewSequence(
+		New(0, 2, 2, nil, set),
+		Wait(2),
+		Call(func() { calls++ }),
+		New(0, 10, 2, nil, set).FromFunc(func() float64 { return value }),
+	)
+	expected := []float64{1, 2, 2, 2, 2, 6, 10}
+	for i, expectedValue := range expected {
+		done := sequence.Update()
+		assert.Equal(t, expectedValue, value, "tick %d", i)
+		assert.Equal(t, i == len(expected)-1, done, "tick %d", i)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestParallel(t *testing.T) {
+	a, b := 0.0, 0.0
+	completed := 0
+	parallel := NewParallel(
+		New(0, 1, 1, nil, func(v float64) { a = v }),
+		New(0, 3, 3, nil, func(v float64) { b = v }),
+	).OnComplete(func() { completed++ })
+	assert.False(t, parallel.Update())
+	assert.Equal(t, 1.0, a)
+	assert.Equal(t, 1.0, b)
+	assert.False(t, parallel.Update())
+	assert.True(t, parallel.Update())
+	assert.Equal(t, 3.0, b)
+	assert.True(t, parallel.Update())
+	assert.Equal(t, 1, completed)
+}
+
+func TestRepeat(t *testing.T) {
+	count := 0
+	repeat := NewRepeat(Call(func() { count++ }), 3)
+	for i := 0; i < 5; i++ {
+		repeat.Update()
+	}
+	assert.Equal(t, 3, count)
+	assert.True(t, repeat.Done())
+
+	forever := NewRepeat(Wait(2), -1)
+	for i := 0; i < 100; i++ {
+		assert.False(t, forever.Update())
+	}
+}
+
+func TestSpriteTweens(t *testing.T) {
+	sprite := lib.NewSprite(lib.XCentre, lib.YCentre).MoveTo(0, 100)
+	tween := NewParallel(
+		MoveTo(sprite, 100, 0, 10, OutQuad),
+		ScaleTo(sprite, 2, 3, 10, nil),
+		FadeTo(sprite, 0, 10, InQuad),
+		RotateTo(sprite, 1, 10, nil),
+	)
+	for !tween.Update() {
+	}
+	assert.Equal(t, 100.0, sprite.RawX())
+	assert.Equal(t, 0.0, sprite.RawY())
+	scaleX, scaleY := sprite.Scale()
+	assert.Equal(t, 2.0, scaleX)
+	assert.Equal(t, 3.0, scaleY)
+	assert.Equal(t, 0.0, sprite.Alpha())
+	assert.Equal(t, 1.0, sprite.Rotation())
+}
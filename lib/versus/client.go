@@ -0,0 +1,175 @@
+package versus
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Client is the connection of one player to the server
+type Client struct {
+	peer     *peer
+	seed     int64
+	player   int
+	mu       sync.Mutex
+	attacks  []Attack // sorted by tick
+	opponent Opponent
+	sendMu   sync.Mutex
+}
+
+// Dial connects to the server and waits for an opponent (up to wait). It returns once the game can start.
+func Dial(addr string, version int, wait time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, Timeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{peer: newPeer(conn)}
+	err = client.peer.send(Message{Type: TypeHello, Version: version})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// the server doesn't send anything until the opponent arrives
+	conn.SetReadDeadline(time.Now().Add(wait))
+	line, err := client.peer.reader.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("waiting for an opponent: %w", err)
+	}
+	start, err := decode(line)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	switch start.Type {
+	case TypeStart:
+	case TypeError:
+		conn.Close()
+		if start.Version != version {
+			return nil, VersionError{Client: version, Server: start.Version}
+		}
+		return nil, errors.New(start.Error)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unexpected message %q during handshake", start.Type)
+	}
+	client.seed = start.Seed
+	client.player = start.Player
+	go client.receive()
+	return client, nil
+}
+
+// Seed returns the random seed shared by both boards
+func (c *Client) Seed() int64 {
+	return c.seed
+}
+
+// Player returns 1 or 2
+func (c *Client) Player() int {
+	return c.player
+}
+
+func (c *Client) receive() {
+	for {
+		message, err := c.peer.receive()
+		if err != nil {
+			c.disconnected()
+			return
+		}
+		c.mu.Lock()
+		switch message.Type {
+		case TypeAttack:
+			c.schedule(message)
+		case TypeState:
+			c.opponent.Tick = message.Tick
+			c.opponent.Score = message.Score
+		case TypeGameOver:
+			c.opponent.Tick = message.Tick
+			c.opponent.Score = message.Score
+			c.opponent.Out = true
+		case TypeBye:
+			c.opponent.Disconnected = true
+		}
+		c.mu.Unlock()
+		if message.Type == TypeBye {
+			c.peer.conn.Close()
+			return
+		}
+	}
+}
+
+func (c *Client) disconnected() {
+	c.mu.Lock()
+	c.opponent.Disconnected = true
+	c.mu.Unlock()
+	c.peer.conn.Close()
+}
+
+// schedule keeps the attacks sorted by the tick they land on. Must be called with the lock held.
+func (c *Client) schedule(message Message) {
+	attack := Attack{Tick: message.Tick + AttackDelay, Kind: message.Kind, Count: message.Count}
+	index := sort.Search(len(c.attacks), func(i int) bool { return c.attacks[i].Tick > attack.Tick })
+	c.attacks = append(c.attacks, Attack{})
+	copy(c.attacks[index+1:], c.attacks[index:])
+	c.attacks[index] = attack
+}
+
+// Attacks returns the attacks landing on the local board at or before this tick, and forgets them.
+// An attack arriving late (after its tick) lands straight away.
+func (c *Client) Attacks(tick int) []Attack {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for count < len(c.attacks) && c.attacks[count].Tick <= tick {
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	due := make([]Attack, count)
+	copy(due, c.attacks)
+	c.attacks = c.attacks[count:]
+	return due
+}
+
+// Opponent returns the last known state of the other player
+func (c *Client) Opponent() Opponent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.opponent
+}
+
+func (c *Client) send(message Message) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	err := c.peer.send(message)
+	if err != nil {
+		c.disconnected()
+	}
+	return err
+}
+
+// SendAttack sends an attack to the opponent, from the local tick
+func (c *Client) SendAttack(tick int, kind AttackKind, count int) error {
+	return c.send(Message{Type: TypeAttack, Tick: tick, Kind: kind, Count: count})
+}
+
+// SendState sends the local score. It also serves as a heartbeat: it must be sent more often than Timeout.
+func (c *Client) SendState(tick, score int) error {
+	return c.send(Message{Type: TypeState, Tick: tick, Score: score})
+}
+
+// SendGameOver tells the opponent the local player lost all their lives
+func (c *Client) SendGameOver(tick, score int) error {
+	return c.send(Message{Type: TypeGameOver, Tick: tick, Score: score})
+}
+
+// Close leaves the game
+func (c *Client) Close() error {
+	c.send(Message{Type: TypeBye})
+	return c.peer.conn.Close()
+}
@@ -0,0 +1,79 @@
+// Package versus is the protocol of the networked versus mode: each player runs their own board,
+// and sends attacks to the opponent. Messages are JSON objects, one per line, over TCP.
+//
+// A client opens the connection with a "hello" message carrying the protocol version. Once two clients are
+// connected, the server sends each of them a "start" message with the random seed shared by both boards.
+// From then on the server relays every message to the opponent, and sends "bye" when the opponent leaves.
+package versus
+
+import (
+	"fmt"
+	"time"
+)
+
+// Message types
+const (
+	TypeHello    = "hello"
+	TypeStart    = "start"
+	TypeAttack   = "attack"
+	TypeState    = "state"
+	TypeGameOver = "gameover"
+	TypeBye      = "bye"
+	TypeError    = "error"
+)
+
+// AttackKind is what an attack sends to the opponent's board
+type AttackKind string
+
+// AttackKind
+const (
+	AttackRocks    AttackKind = "rocks"
+	AttackSegments AttackKind = "segments"
+)
+
+const (
+	// AttackDelay is the number of ticks between sending an attack and the opponent receiving it.
+	// It leaves time for the message to travel, so the attack lands on the same tick whatever the latency.
+	AttackDelay = 30
+	// Timeout is how long without any message before the opponent is considered disconnected.
+	// Clients are expected to send their state more often than that.
+	Timeout = 5 * time.Second
+)
+
+// Message is the single envelope of all the messages exchanged
+type Message struct {
+	Type    string     `json:"type"`
+	Version int        `json:"version,omitempty"`
+	Seed    int64      `json:"seed,omitempty"`
+	Player  int        `json:"player,omitempty"`
+	Tick    int        `json:"tick,omitempty"`
+	Kind    AttackKind `json:"kind,omitempty"`
+	Count   int        `json:"count,omitempty"`
+	Score   int        `json:"score,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Attack received from the opponent, to apply on the local board at Tick
+type Attack struct {
+	Tick  int
+	Kind  AttackKind
+	Count int
+}
+
+// Opponent is the last known state of the other player
+type Opponent struct {
+	Tick         int
+	Score        int
+	Out          bool // lost all their lives
+	Disconnected bool
+}
+
+// VersionError is returned when the client and server don't speak the same version of the protocol
+type VersionError struct {
+	Client int
+	Server int
+}
+
+func (e VersionError) Error() string {
+	return fmt.Sprintf("protocol version mismatch: client %d, server %d", e.Client, e.Server)
+}
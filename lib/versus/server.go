@@ -0,0 +1,199 @@
+package versus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server pairs the clients two by two, and relays the messages between them
+type Server struct {
+	listener net.Listener
+	version  int
+	mu       sync.Mutex
+	waiting  *peer
+	closed   bool
+}
+
+type peer struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	encoder *json.Encoder
+	watched chan struct{} // closed once the server stops watching the peer waiting for an opponent
+}
+
+func newPeer(conn net.Conn) *peer {
+	return &peer{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		encoder: json.NewEncoder(conn),
+	}
+}
+
+func (p *peer) send(message Message) error {
+	p.conn.SetWriteDeadline(time.Now().Add(Timeout))
+	return p.encoder.Encode(message)
+}
+
+func (p *peer) receive() (Message, error) {
+	p.conn.SetReadDeadline(time.Now().Add(Timeout))
+	line, err := p.reader.ReadBytes('\n')
+	if err != nil {
+		return Message{}, err
+	}
+	return decode(line)
+}
+
+func decode(line []byte) (Message, error) {
+	message := Message{}
+	err := json.Unmarshal(line, &message)
+	return message, err
+}
+
+// Listen on the TCP address (e.g. ":7777") for clients speaking this version of the protocol
+func Listen(addr string, version int) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		listener: listener,
+		version:  version,
+	}, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve accepts clients until the server is closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.handshake(newPeer(conn))
+	}
+}
+
+// Close stops accepting new clients
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	if s.waiting != nil {
+		s.waiting.conn.Close()
+		s.waiting = nil
+	}
+	s.mu.Unlock()
+	return s.listener.Close()
+}
+
+func (s *Server) handshake(client *peer) {
+	hello, err := client.receive()
+	if err != nil || hello.Type != TypeHello {
+		log.Printf("versus: %s: invalid handshake: %v", client.conn.RemoteAddr(), err)
+		client.conn.Close()
+		return
+	}
+	if hello.Version != s.version {
+		client.send(Message{Type: TypeError, Version: s.version, Error: VersionError{hello.Version, s.version}.Error()})
+		client.conn.Close()
+		return
+	}
+
+	s.mu.Lock()
+	opponent := s.waiting
+	if opponent == nil {
+		s.waiting = client
+		client.watched = make(chan struct{})
+		s.mu.Unlock()
+		log.Printf("versus: %s waiting for an opponent", client.conn.RemoteAddr())
+		s.watch(client)
+		return
+	}
+	s.waiting = nil
+	s.mu.Unlock()
+
+	// interrupt the watch: from now on the opponent's messages are relayed
+	opponent.conn.SetReadDeadline(time.Now())
+	<-opponent.watched
+	go s.play(opponent, client)
+}
+
+// watch the client waiting for an opponent, so it's not paired once gone. A waiting client doesn't send
+// anything: the read only returns when the connection is closed, or when interrupted by the pairing.
+func (s *Server) watch(client *peer) {
+	defer close(client.watched)
+	client.conn.SetReadDeadline(time.Time{})
+	_, err := client.reader.Peek(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.waiting != client {
+		// paired (or the server is closed)
+		return
+	}
+	s.waiting = nil
+	client.conn.Close()
+	if err == nil {
+		err = errors.New("unexpected message")
+	}
+	log.Printf("versus: %s left while waiting: %v", client.conn.RemoteAddr(), err)
+}
+
+// play relays the messages between the two players until one of them leaves
+func (s *Server) play(player1, player2 *peer) {
+	log.Printf("versus: game between %s and %s", player1.conn.RemoteAddr(), player2.conn.RemoteAddr())
+	seed := rand.Int63()
+	err1 := player1.send(Message{Type: TypeStart, Seed: seed, Player: 1})
+	err2 := player2.send(Message{Type: TypeStart, Seed: seed, Player: 2})
+	if err1 != nil || err2 != nil {
+		player1.conn.Close()
+		player2.conn.Close()
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go relay(player1, player2, done)
+	go relay(player2, player1, done)
+	<-done
+	// one side is gone: close both so the other relay stops too
+	player1.conn.Close()
+	player2.conn.Close()
+	<-done
+}
+
+func relay(from, to *peer, done chan<- struct{}) {
+	defer func() { done <- struct{}{} }()
+	for {
+		message, err := from.receive()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("versus: %s left: %v", from.conn.RemoteAddr(), err)
+			}
+			to.send(Message{Type: TypeBye})
+			return
+		}
+		if message.Type == TypeBye {
+			to.send(message)
+			return
+		}
+		err = to.send(message)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package versus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVersion = 3
+
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	server, err := Listen("127.0.0.1:0", testVersion)
+	require.NoError(t, err)
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// connectPair connects two clients to the server
+func connectPair(t *testing.T, server *Server) (*Client, *Client) {
+	t.Helper()
+	clients := make([]*Client, 2)
+	errs := make([]error, 2)
+	wg := sync.WaitGroup{}
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], errs[i] = Dial(server.Addr().String(), testVersion, time.Second)
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	t.Cleanup(func() {
+		clients[0].Close()
+		clients[1].Close()
+	})
+	return clients[0], clients[1]
+}
+
+func TestHandshake(t *testing.T) {
+	server := startServer(t)
+	client1, client2 := connectPair(t, server)
+
+	assert.Equal(t, client1.Seed(), client2.Seed())
+	assert.ElementsMatch(t, []int{1, 2}, []int{client1.Player(), client2.Player()})
+}
+
+func TestVersionMismatch(t *testing.T) {
+	server := startServer(t)
+	_, err := Dial(server.Addr().String(), testVersion+1, time.Second)
+	require.Error(t, err)
+	assert.Equal(t, VersionError{Client: testVersion + 1, Server: testVersion}, err)
+}
+
+func TestNoOpponent(t *testing.T) {
+	server := startServer(t)
+	_, err := Dial(server.Addr().String(), testVersion, 50*time.Millisecond)
+	assert.Error(t, err)
+
+	// the client gave up: it's no longer waiting
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return server.waiting == nil
+	}, time.Second, time.Millisecond)
+
+	// and the next two clients play together
+	client1, client2 := connectPair(t, server)
+	require.NoError(t, client1.SendState(30, 100))
+	require.Eventually(t, func() bool { return client2.Opponent().Score == 100 }, time.Second, time.Millisecond)
+	assert.False(t, client2.Opponent().Disconnected)
+}
+
+func TestAttackLandsAfterDelay(t *testing.T) {
+	server := startServer(t)
+	client1, client2 := connectPair(t, server)
+
+	require.NoError(t, client1.SendAttack(20, AttackRocks, 3))
+	require.NoError(t, client1.SendAttack(10, AttackSegments, 2))
+
+	require.Eventually(t, func() bool {
+		client2.mu.Lock()
+		defer client2.mu.Unlock()
+		return len(client2.attacks) == 2
+	}, time.Second, time.Millisecond)
+
+	assert.Empty(t, client2.Attacks(10+AttackDelay-1))
+	assert.Equal(t, []Attack{{Tick: 10 + AttackDelay, Kind: AttackSegments, Count: 2}}, client2.Attacks(10+AttackDelay))
+	// late: lands straight away
+	assert.Equal(t, []Attack{{Tick: 20 + AttackDelay, Kind: AttackRocks, Count: 3}}, client2.Attacks(100))
+	assert.Empty(t, client2.Attacks(200))
+	assert.Empty(t, client1.Attacks(200), "attacks are not sent back to the sender")
+}
+
+func TestStateAndGameOver(t *testing.T) {
+	server := startServer(t)
+	client1, client2 := connectPair(t, server)
+
+	require.NoError(t, client1.SendState(60, 1234))
+	require.Eventually(t, func() bool { return client2.Opponent().Score == 1234 }, time.Second, time.Millisecond)
+	assert.Equal(t, 60, client2.Opponent().Tick)
+	assert.False(t, client2.Opponent().Out)
+
+	require.NoError(t, client1.SendGameOver(90, 2000))
+	require.Eventually(t, func() bool { return client2.Opponent().Out }, time.Second, time.Millisecond)
+	assert.Equal(t, 2000, client2.Opponent().Score)
+}
+
+func TestDisconnect(t *testing.T) {
+	server := startServer(t)
+	client1, client2 := connectPair(t, server)
+
+	client1.Close()
+	require.Eventually(t, func() bool { return client2.Opponent().Disconnected }, time.Second, time.Millisecond)
+	assert.Error(t, client2.SendState(1, 0), "the server closed the connection")
+}
+
+// headless runs a fake board: it sends an attack every few ticks and counts the attacks landing on it
+type headless struct {
+	client   *Client
+	sent     int
+	received int
+	lateness int // total number of ticks attacks landed after their scheduled tick
+}
+
+func (h *headless) run(ticks int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(2 * time.Millisecond)
+	defer ticker.Stop()
+	for tick := 0; tick < ticks; tick++ {
+		<-ticker.C
+		for _, attack := range h.client.Attacks(tick) {
+			h.received += attack.Count
+			h.lateness += tick - attack.Tick
+		}
+		if tick%10 == 0 && tick < ticks-2*AttackDelay {
+			h.client.SendAttack(tick, AttackRocks, 1)
+			h.sent++
+		}
+		if tick%25 == 0 {
+			h.client.SendState(tick, h.received)
+		}
+	}
+}
+
+func TestTwoHeadlessInstances(t *testing.T) {
+	server := startServer(t)
+	client1, client2 := connectPair(t, server)
+	instances := []*headless{{client: client1}, {client: client2}}
+
+	wg := sync.WaitGroup{}
+	for _, instance := range instances {
+		wg.Add(1)
+		go instance.run(300, &wg)
+	}
+	wg.Wait()
+
+	assert.Greater(t, instances[0].sent, 0)
+	assert.Equal(t, instances[0].sent, instances[1].received)
+	assert.Equal(t, instances[1].sent, instances[0].received)
+	// over loopback, attacks are never late
+	assert.Zero(t, instances[0].lateness)
+	assert.Zero(t, instances[1].lateness)
+}
@@ -21,3 +21,15 @@ func (x XType) String() string {
 		return "X left"
 	}
 }
+
+// offset returns the distance between the left of an image and a coordinate of this type
+func (x XType) offset(width float64) float64 {
+	switch x {
+	case XCentre:
+		return width / 2
+	case XRight:
+		return width
+	default:
+		return 0
+	}
+}
@@ -21,3 +21,15 @@ func (y YType) String() string {
 		return "Y top"
 	}
 }
+
+// offset returns the distance between the top of an image and a coordinate of this type
+func (y YType) offset(height float64) float64 {
+	switch y {
+	case YCentre:
+		return height / 2
+	case YBottom:
+		return height
+	default:
+		return 0
+	}
+}
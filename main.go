@@ -2,30 +2,96 @@ package main
 
 import (
 	"flag"
+	"io/fs"
 	"log"
+	"os"
+	"time"
 
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/cavern/creativeprojects/myriapod/lib/spectate"
+	"github.com/cavern/creativeprojects/myriapod/lib/versus"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 )
 
 var (
-	images map[string]*ebiten.Image
-	sounds map[string][]byte
+	images     map[string]*ebiten.Image
+	sounds     map[string][]byte
+	animations *lib.AnimationSet
+	textFont   *font.Font
+	// powerUpConfig defines the power-ups dropped by the totems in each wave
+	powerUpConfig *PowerUpConfig
+	// bossConfig lists the boss waves
+	bossConfig *BossConfig
 )
 
 func main() {
 	var err error
+	var assetsDir string
+	var noEffects bool
+	var extraLifeEvery int
+	var extraLifeAt string
+	var weaponName string
+	var sharedLives bool
+	var versusAddr string
+	var netplayPeer string
+	var netplayAddr string
+	var netplayPlayer int
+	var seed int64
+	var spectateServerAddr string
+	var spectateAddr string
+	var leaderboardURL string
+	var playerName string
+	var replayFile string
 
 	if DebugBuild {
 		flag.BoolVar(&Debug, "d", false, "Debug mode")
-		flag.Parse()
+		flag.StringVar(&assetsDir, "assets", "", "Reload images and sounds from this directory when they change")
 	}
+	flag.BoolVar(&noEffects, "no-effects", false, "Disable screen shake, hit-stop and flash (can also be toggled with the E key)")
+	flag.IntVar(&extraLifeEvery, "extra-life-every", ExtraLifeEvery, "Extra life every n points (0 to disable), after the scores in -extra-life-at")
+	flag.StringVar(&extraLifeAt, "extra-life-at", "", "Comma separated list of scores giving an extra life")
+	flag.StringVar(&weaponName, "weapon", Cannon.Name, "Starting weapon: cannon, spread, laser or charge")
+	flag.BoolVar(&sharedLives, "shared-lives", false, "In a two player game, both players take their lives from the same pool")
+	flag.StringVar(&versusAddr, "versus", "", "Play a versus game: address of the versus server (host:port)")
+	flag.StringVar(&netplayPeer, "netplay", "", "Play online co-op: address of the other player (host:port)")
+	flag.StringVar(&netplayAddr, "netplay-listen", NetplayAddr, "Local address of an online co-op game")
+	flag.IntVar(&netplayPlayer, "netplay-player", 1, "Player on this side of an online co-op game: 1 or 2 (the other side must be the other one)")
+	flag.Int64Var(&seed, "seed", 1, "Random seed of an online co-op game: must be the same on both sides")
+	flag.StringVar(&spectateServerAddr, "spectate-server", "", "Stream the games to spectators connecting to this address (host:port)")
+	flag.StringVar(&spectateAddr, "spectate", "", "Watch the game streamed from this address (host:port)")
+	flag.StringVar(&leaderboardURL, "leaderboard", "", "Submit the scores to the leaderboard at this URL (http://host:port)")
+	flag.StringVar(&playerName, "name", defaultPlayerName(), "Name on the leaderboard")
+	flag.StringVar(&replayFile, "save-replay", "", "Save the replay of each game in this file, to be checked with the verify-replay command")
+	flag.Parse()
 
-	images, err = loadImages()
+	if flag.Arg(0) == "validate-assets" {
+		os.Exit(validateAssetsCommand(flag.Arg(1)))
+	}
+	if flag.Arg(0) == "versus-server" {
+		os.Exit(versusServerCommand(flag.Arg(1)))
+	}
+	if flag.Arg(0) == "verify-replay" {
+		os.Exit(verifyReplayCommand(flag.Arg(1)))
+	}
+	if flag.Arg(0) == "leaderboard-server" {
+		os.Exit(leaderboardServerCommand(flag.Arg(1), flag.Arg(2)))
+	}
+
+	err = loadResources()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if assetsDir != "" {
+		err = WatchAssets(assetsDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	audioContext := audio.NewContext(SampleRate)
 
 	sounds, err = loadSounds()
@@ -40,7 +106,109 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	game.effects.SetEnabled(!noEffects)
+	game.extraLifeRule.Every = extraLifeEvery
+	game.extraLifeRule.Scores, err = ParseExtraLifeScores(extraLifeAt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	weapon, found := Weapons[weaponName]
+	if !found {
+		log.Fatalf("unknown weapon %q", weaponName)
+	}
+	game.weapon = weapon
+	game.sharedLives = sharedLives
+	game.leaderboard = leaderboardURL
+	game.playerName = playerName
+	game.replayFile = replayFile
+	if versusAddr != "" {
+		log.Printf("waiting for an opponent on %s", versusAddr)
+		game.versus, err = versus.Dial(versusAddr, VersusVersion, VersusWaitTime*time.Second)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.Start(1, false)
+	}
+	if netplayPeer != "" {
+		transport, err := rollback.ListenUDP(netplayAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = transport.Connect(netplayPeer)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = game.StartNetplay(transport, netplayPlayer-1, seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if spectateServerAddr != "" {
+		game.spectators, err = spectate.Listen(spectateServerAddr, SpectateVersion)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("streaming to spectators on %s", game.spectators.Addr())
+		go func() {
+			if err := game.spectators.Serve(); err != nil {
+				log.Print(err)
+			}
+		}()
+	}
+	if spectateAddr != "" {
+		client, err := spectate.Dial(spectateAddr, SpectateVersion)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.Spectate(client)
+	}
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// loadResources loads everything the game needs, but the sounds
+func loadResources() error {
+	var err error
+	images, err = loadImages()
+	if err != nil {
+		return err
+	}
+	buildFrameTables()
+
+	animations, err = loadAnimations()
+	if err != nil {
+		return err
+	}
+
+	textFont, err = loadFont()
+	if err != nil {
+		return err
+	}
+
+	powerUpConfig, err = loadPowerUps()
+	if err != nil {
+		return err
+	}
+
+	bossConfig, err = loadBosses()
+	return err
+}
+
+// validateAssetsCommand checks the embedded assets, or the assets in dir if not empty. It returns the exit code.
+func validateAssetsCommand(dir string) int {
+	var fsys fs.FS = embeddedFiles
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+	report, err := validateAssets(fsys)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	report.Write(os.Stdout)
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"log"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Netplay is an online co-op game: both peers run the whole game, and only exchange their inputs
+type Netplay struct {
+	session *rollback.Session
+	local   int // index of the player on this side
+	seed    int64
+	desync  bool
+	lost    bool // nothing received from the peer for a while
+}
+
+// StartNetplay starts a co-op game with a peer. local is the index of the player on this side (0 or 1),
+// and both sides must use the same seed.
+func (g *Game) StartNetplay(transport rollback.Transport, local int, seed int64) error {
+	session, err := rollback.NewSession(rollbackGame{g}, transport, local, NetplayInputDelay)
+	if err != nil {
+		return err
+	}
+	g.netplay = &Netplay{
+		session: session,
+		local:   local,
+		seed:    seed,
+	}
+	g.Start(rollback.Players, false)
+	return nil
+}
+
+// Close the connection to the peer
+func (n *Netplay) Close() {
+	n.session.Close()
+}
+
+// updateNetplay sends the local input to the session, which simulates the next tick (and any tick to correct)
+func (g *Game) updateNetplay() {
+	var err error
+	if g.state == StatePlaying {
+		_, err = g.netplay.session.Advance(readInput(playerControls()[0]))
+	} else {
+		err = g.netplay.session.Poll()
+	}
+	if err != nil {
+		log.Printf("netplay: %v", err)
+	}
+	if tick := g.netplay.session.Desync(); tick >= 0 && !g.netplay.desync {
+		g.netplay.desync = true
+		log.Printf("netplay: game state differs from the peer's since tick %d", tick)
+		g.hud.Announce("DESYNC")
+	}
+	if g.netplay.session.PeerLost() && !g.netplay.lost {
+		g.netplay.lost = true
+		log.Print("netplay: connection to the peer lost")
+		g.hud.Announce("CONNECTION LOST")
+	}
+}
+
+// drawNetplay shows the way back to the menu once the peer is gone: the game can't go on without it
+func (g *Game) drawNetplay(screen *ebiten.Image) {
+	if g.netplay == nil || !g.netplay.lost {
+		return
+	}
+	textFont.Draw(screen, "CONNECTION LOST", WindowWidth/2, WindowHeight/2-40, &font.DrawOptions{Align: font.AlignCentre, Scale: 2})
+	textFont.Draw(screen, "PRESS SPACE FOR THE MENU", WindowWidth/2, WindowHeight/2+20, &font.DrawOptions{Align: font.AlignCentre})
+}
+
+// readInput converts the state of the controls into a netplay input
+func readInput(controls Controls) rollback.Input {
+	var input rollback.Input
+	if controls.Left() {
+		input |= rollback.InputLeft
+	}
+	if controls.Right() {
+		input |= rollback.InputRight
+	}
+	if controls.Up() {
+		input |= rollback.InputUp
+	}
+	if controls.Down() {
+		input |= rollback.InputDown
+	}
+	if controls.Fire() {
+		input |= rollback.InputFire
+	}
+	return input
+}
+
+// NetControls reads the controls of a player from the inputs of the tick being simulated
+type NetControls struct {
+	game  *Game
+	index int
+}
+
+func (n NetControls) Left() bool  { return n.pressed(rollback.InputLeft) }
+func (n NetControls) Right() bool { return n.pressed(rollback.InputRight) }
+func (n NetControls) Up() bool    { return n.pressed(rollback.InputUp) }
+func (n NetControls) Down() bool  { return n.pressed(rollback.InputDown) }
+func (n NetControls) Fire() bool  { return n.pressed(rollback.InputFire) }
+
+func (n NetControls) pressed(input rollback.Input) bool {
+	return n.game.netInputs[n.index]&input != 0
+}
+
+func netControls(g *Game) [2]Controls {
+	return [2]Controls{NetControls{g, 0}, NetControls{g, 1}}
+}
+
+// rollbackGame runs the game from a rollback session
+type rollbackGame struct {
+	game *Game
+}
+
+func (r rollbackGame) Step(inputs []rollback.Input, replay bool) {
+	g := r.game
+	if g.state != StatePlaying {
+		// game over
+		return
+	}
+	copy(g.netInputs[:], inputs)
+	g.replaying = replay
+	g.effects.SetMuted(replay)
+	g.step()
+	g.replaying = false
+	g.effects.SetMuted(false)
+}
+
+func (r rollbackGame) Save() any {
+	return r.game.Snapshot()
+}
+
+func (r rollbackGame) Load(snapshot any) {
+	snapshot.(*Snapshot).Restore()
+}
+
+func (r rollbackGame) Hash() uint64 {
+	return r.game.StateHash()
+}
+
+// Snapshot is a copy of the game state. The objects are restored at the same address,
+// so the pointers between them (e.g. the owner of a bullet) stay valid.
+type Snapshot struct {
+	restore []func()
+}
+
+// keep a copy of the value at ptr, to write back on Restore
+func keep[T any](s *Snapshot, ptr *T) {
+	value := *ptr
+	s.restore = append(s.restore, func() { *ptr = value })
+}
+
+// keepSlice keeps a copy of the slice content: elements are removed in place
+func keepSlice[T any](s *Snapshot, ptr *[]T) {
+	value := slices.Clone(*ptr)
+	s.restore = append(s.restore, func() { *ptr = slices.Clone(value) })
+}
+
+func keepSprite[T any](s *Snapshot, ptr *T, sprite *lib.Sprite) {
+	keep(s, ptr)
+	keep(s, sprite)
+}
+
+// Snapshot saves the state of the game in play. Only what changes the game is saved: not the explosions,
+// particles, popups or screen effects.
+func (g *Game) Snapshot() *Snapshot {
+	s := &Snapshot{}
+	source := g.randomSource.State()
+	s.restore = append(s.restore, func() { g.randomSource.SetState(source) })
+	keep(s, &g.state)
+	keep(s, &g.wave)
+	keep(s, &g.time)
+	keep(s, &g.ticks)
+	keep(s, &g.lives)
+	keep(s, &g.combo)
+	keep(s, &g.comboTime)
+	keep(s, &g.highScore)
+	poisonTrail := maps.Clone(g.poisonTrail)
+	s.restore = append(s.restore, func() { g.poisonTrail = maps.Clone(poisonTrail) })
+
+	for y := range g.grid {
+		keepSlice(s, &g.grid[y])
+		for _, rock := range g.grid[y] {
+			if rock != nil {
+				keepSprite(s, rock, rock.sprite)
+			}
+		}
+	}
+	keepSlice(s, &g.segments)
+	for _, segment := range g.segments {
+		keepSprite(s, segment, segment.sprite)
+	}
+	keep(s, &g.boss)
+	if g.boss != nil {
+		keep(s, g.boss)
+	}
+	for _, player := range g.players {
+		keepSprite(s, player, player.sprite)
+	}
+	keepSlice(s, &g.bullets)
+	for _, bullet := range g.bullets {
+		keepSprite(s, bullet, bullet.sprite)
+	}
+	keepSlice(s, &g.powerUps)
+	for _, powerUp := range g.powerUps {
+		keepSprite(s, powerUp, powerUp.sprite)
+	}
+	for _, enemy := range g.enemies {
+		switch e := enemy.(type) {
+		case *FlyingEnemy:
+			keepSprite(s, e, e.sprite)
+		case *Dropper:
+			keepSprite(s, e, e.sprite)
+		case *Crawler:
+			keepSprite(s, e, e.sprite)
+		}
+	}
+	return s
+}
+
+// Restore the game to the state of the snapshot
+func (s *Snapshot) Restore() {
+	for _, restore := range s.restore {
+		restore()
+	}
+}
+
+// StateHash returns a hash of the game in play, to check that two games are the same
+func (g *Game) StateHash() uint64 {
+	buffer := make([]byte, 0, 4096)
+	ints := func(values ...int) {
+		for _, value := range values {
+			buffer = binary.LittleEndian.AppendUint64(buffer, uint64(value))
+		}
+	}
+	floats := func(values ...float64) {
+		for _, value := range values {
+			buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(value))
+		}
+	}
+	bools := func(values ...bool) {
+		for _, value := range values {
+			if value {
+				buffer = append(buffer, 1)
+			} else {
+				buffer = append(buffer, 0)
+			}
+		}
+	}
+
+	buffer = binary.LittleEndian.AppendUint64(buffer, g.randomSource.State())
+	ints(int(g.state), g.wave, g.time, g.ticks, g.lives, g.combo)
+	for _, player := range g.players {
+		ints(player.score, player.lives, player.timer, player.fireTimer, player.charge)
+		ints(player.powerUps[:]...)
+		bools(player.alive)
+		floats(player.sprite.RawX(), player.sprite.RawY())
+	}
+	for _, row := range g.grid {
+		for _, rock := range row {
+			if rock != nil {
+				ints(rock.cellX, rock.cellY, rock.health)
+				bools(rock.isTotem, rock.poisoned)
+			}
+		}
+	}
+	for _, segment := range g.segments {
+		ints(segment.cx, segment.cy, segment.health, int(segment.direction))
+		floats(segment.posX, segment.posY)
+	}
+	for _, bullet := range g.bullets {
+		if !bullet.done {
+			floats(bullet.sprite.RawX(), bullet.sprite.RawY())
+		}
+	}
+	for _, powerUp := range g.powerUps {
+		if !powerUp.done {
+			ints(int(powerUp.kind))
+			floats(powerUp.sprite.RawX(), powerUp.sprite.RawY())
+		}
+	}
+	for _, enemy := range g.enemies {
+		bools(enemy.IsInactive())
+		if !enemy.IsInactive() {
+			floats(enemy.Position())
+		}
+	}
+	h := fnv.New64a()
+	h.Write(buffer)
+	return h.Sum64()
+}
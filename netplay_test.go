@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/particle"
+	"github.com/cavern/creativeprojects/myriapod/lib/random"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNetplayGame() *Game {
+	game := &Game{
+		particles: particle.NewSystem(10),
+		effects:   NewEffects(),
+		state:     StatePlaying,
+	}
+	game.random, game.randomSource = random.New(1)
+	game.hud = &HUD{game: game}
+	game.newGrid()
+	controls := netControls(game)
+	game.players = []*Player{
+		NewPlayer(game, 0, 2, controls[0]),
+		NewPlayer(game, 1, 2, controls[1]),
+	}
+	return game
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	game := newNetplayGame()
+	rock := NewRock(game, 3, 4, false)
+	game.grid[4][3] = rock
+	segment := NewSegment(game, 5, 0, 1, false, true)
+	game.segments = append(game.segments, segment)
+	game.wave = 2
+
+	hash := game.StateHash()
+	snapshot := game.Snapshot()
+	expected := game.random.Int63()
+
+	// the game goes on...
+	rock.health = 0
+	game.grid[4][3] = nil
+	game.segments = game.segments[:0]
+	game.players[0].sprite.Move(10, 0)
+	game.players[1].score = 100
+	game.wave = 3
+	assert.NotEqual(t, hash, game.StateHash())
+
+	// ...and goes back
+	snapshot.Restore()
+	assert.Equal(t, hash, game.StateHash())
+	require.Same(t, rock, game.grid[4][3])
+	assert.Greater(t, rock.health, 0)
+	require.Len(t, game.segments, 1)
+	assert.Same(t, segment, game.segments[0])
+	assert.Equal(t, 0, game.players[1].score)
+	assert.Equal(t, 2, game.wave)
+	assert.Equal(t, expected, game.random.Int63())
+}
+
+func TestNetControls(t *testing.T) {
+	game := newNetplayGame()
+	game.netInputs = [rollback.Players]rollback.Input{rollback.InputLeft | rollback.InputFire, rollback.InputDown}
+
+	player1 := game.players[0].controls
+	assert.True(t, player1.Left())
+	assert.True(t, player1.Fire())
+	assert.False(t, player1.Right())
+	player2 := game.players[1].controls
+	assert.True(t, player2.Down())
+	assert.False(t, player2.Fire())
+
+	assert.Equal(t, game.netInputs[0], readInput(player1))
+	assert.Equal(t, game.netInputs[1], readInput(player2))
+}
+
+// netplayTestInput changes every few ticks, so the predictions are sometimes wrong
+func netplayTestInput(player, tick int) rollback.Input {
+	return rollback.Input((tick/7 + player*3) * 2654435761 >> 8 % 32)
+}
+
+func TestNetplayOverLossyLink(t *testing.T) {
+	const ticks = 900
+	require.NoError(t, loadTestResources())
+	link := rollback.NewLink(3, 2, .2, 1)
+	a, b := link.Transports()
+	games := []*Game{NewHeadlessGame(), NewHeadlessGame()}
+	for player, transport := range []rollback.Transport{a, b} {
+		require.NoError(t, games[player].StartNetplay(transport, player, 42))
+	}
+
+	for step := 0; step < ticks*20; step++ {
+		if games[0].netplay.session.Confirmed() >= ticks && games[1].netplay.session.Confirmed() >= ticks {
+			break
+		}
+		for player, game := range games {
+			session := game.netplay.session
+			if session.Frame() < ticks {
+				_, err := session.Advance(netplayTestInput(player, session.Frame()))
+				require.NoError(t, err)
+			} else {
+				require.NoError(t, session.Poll())
+			}
+		}
+		link.Tick()
+	}
+
+	for player, game := range games {
+		session := game.netplay.session
+		require.GreaterOrEqual(t, session.Confirmed(), ticks, "player %d", player)
+		assert.Equal(t, -1, session.Desync(), "player %d", player)
+		assert.Greater(t, session.Rollbacks(), 0, "player %d", player)
+	}
+	assert.Equal(t, games[0].StateHash(), games[1].StateHash())
+}
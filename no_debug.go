@@ -21,3 +21,9 @@ func (p *Player) String() string {}
 
 // String returns a debug string
 func (e *FlyingEnemy) String() string {}
+
+// String returns a debug string
+func (e *Dropper) String() string { return "" }
+
+// String returns a debug string
+func (e *Crawler) String() string { return "" }
@@ -0,0 +1,10 @@
+//go:build prod
+
+package main
+
+// WatchAssets is not available in production builds
+func WatchAssets(dir string) error {
+	return nil
+}
+
+func (g *Game) reloadAssets() {}
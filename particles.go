@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/particle"
+)
+
+// MaxParticles is the size of the particle buffer
+const MaxParticles = 2000
+
+var (
+	rockDebris = &particle.Config{
+		Burst:          14,
+		Lifetime:       24,
+		LifetimeSpread: 8,
+		Speed:          2.5,
+		SpeedSpread:    1.5,
+		Angle:          -math.Pi / 2,
+		AngleSpread:    math.Pi / 2,
+		Gravity:        0.25,
+		Size:           3,
+		EndSize:        1,
+		Colours:        []color.RGBA{{0xb0, 0x90, 0x70, 0xff}, {0x60, 0x48, 0x38, 0x00}},
+	}
+	totemBurst = &particle.Config{
+		Burst:          40,
+		Rate:           6,
+		Duration:       10,
+		Lifetime:       30,
+		LifetimeSpread: 10,
+		Speed:          3,
+		SpeedSpread:    2,
+		AngleSpread:    math.Pi,
+		Drag:           0.04,
+		Size:           4,
+		EndSize:        1,
+		Colours:        []color.RGBA{{0xff, 0xff, 0xc0, 0xff}, {0xff, 0xc0, 0x20, 0xff}, {0xc0, 0x20, 0x00, 0x00}},
+	}
+	segmentSplat = &particle.Config{
+		Burst:          20,
+		Lifetime:       20,
+		LifetimeSpread: 6,
+		Speed:          2,
+		SpeedSpread:    1.5,
+		AngleSpread:    math.Pi,
+		Drag:           0.08,
+		Size:           3,
+		Colours:        []color.RGBA{{0xc0, 0xff, 0x40, 0xff}, {0x20, 0x80, 0x20, 0x00}},
+	}
+	playerDebris = &particle.Config{
+		Burst:          60,
+		Rate:           4,
+		Duration:       20,
+		Lifetime:       40,
+		LifetimeSpread: 15,
+		Speed:          4,
+		SpeedSpread:    3,
+		AngleSpread:    math.Pi,
+		Gravity:        0.1,
+		Drag:           0.03,
+		Size:           3,
+		Colours:        []color.RGBA{{0xff, 0xff, 0xff, 0xff}, {0x40, 0xc0, 0xff, 0xff}, {0x20, 0x20, 0x80, 0x00}},
+	}
+)
+
+// Particles emits particles at this position, in addition to the explosion animations
+func (g *Game) Particles(x, y float64, config *particle.Config) {
+	if g.replaying {
+		return
+	}
+	g.particles.Emit(config, x, y)
+}
@@ -1,44 +1,69 @@
 package main
 
 import (
+	"image/color"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/tween"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// player2Tint tells player 2 apart
+var player2Tint = color.RGBA{0x90, 0xff, 0x90, 0xff}
+
 type Player struct {
-	game      *Game
-	sprite    *lib.Sprite
-	life      *ebiten.Image
-	images    [][]*ebiten.Image
-	direction int
-	frame     int
-	lives     int
-	alive     bool
-	timer     int
-	fireTimer int
-	op        *ebiten.DrawImageOptions
+	game          *Game
+	index         int // 0 for player 1, 1 for player 2
+	controls      Controls
+	sprite        *lib.Sprite
+	spawnX        float64
+	score         int
+	nextExtraLife int
+	images        [][]*ebiten.Image
+	direction     int
+	frame         int
+	lives         int
+	alive         bool
+	timer         int
+	fireTimer     int
+	weapon        *Weapon
+	charge        int              // ticks the fire button has been held, for a charged weapon
+	powerUps      [numPowerUps]int // time left for each power-up
+	effect        tween.Tweener
+	op            *ebiten.DrawImageOptions
 }
 
-func NewPlayer(game *Game) *Player {
+// NewPlayer creates player index (0 or 1) of a game with count players
+func NewPlayer(game *Game, index, count int, controls Controls) *Player {
+	// players are spread evenly across the screen
+	spawnX := PlayerSpawnX + (float64(index)-float64(count-1)/2)*PlayerSpawnSpacing
+	sprite := lib.NewSprite(lib.XCentre, lib.YCentre).MoveTo(spawnX, PlayerSpawnY).SetImage(images["player00"])
+	if index > 0 {
+		sprite.SetTint(player2Tint)
+	}
 	return &Player{
-		game:   game,
-		sprite: lib.NewSprite(lib.XCentre, lib.YCentre).MoveTo(PlayerSpawnX, PlayerSpawnY).SetImage(images["player00"]),
-		life:   images["life"],
+		game:          game,
+		index:         index,
+		controls:      controls,
+		sprite:        sprite,
+		spawnX:        spawnX,
+		nextExtraLife: game.extraLifeRule.Next(0),
 		images: [][]*ebiten.Image{
-			{images["player00"], images["player01"], images["player02"]},
-			{images["player10"], images["player11"], images["player12"]},
-			{images["player20"], images["player21"], images["player22"]},
-			{images["player30"], images["player31"], images["player32"]},
+			imageList("player0%d", 3),
+			imageList("player1%d", 3),
+			imageList("player2%d", 3),
+			imageList("player3%d", 3),
 		},
 		direction: 0,
 		frame:     0,
-		lives:     3,
+		lives:     PlayerLives,
 		alive:     true,
 		timer:     0,
 		fireTimer: 0,
+		weapon:    game.weapon,
 		op:        &ebiten.DrawImageOptions{},
 	}
 }
@@ -58,16 +83,16 @@ func (p *Player) Update() {
 	p.timer++
 	if p.alive {
 		var dx, dy float64
-		if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		if p.controls.Left() {
 			dx = -1
 		}
-		if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		if p.controls.Right() {
 			dx = 1
 		}
-		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		if p.controls.Up() {
 			dy = -1
 		}
-		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		if p.controls.Down() {
 			dy = 1
 		}
 		if dx != 0 || dy != 0 {
@@ -81,41 +106,66 @@ func (p *Player) Update() {
 
 		x := p.sprite.X(lib.XCentre)
 		y := p.sprite.Y(lib.YCentre)
+		weapon := p.weapon.WithPowerUps(p.powerUps)
+		firing := p.controls.Fire()
 		p.fireTimer--
-		// Fire cannon (or allow firing animation to finish)
-		if p.fireTimer < 0 && (p.frame > 0 || ebiten.IsKeyPressed(ebiten.KeySpace)) {
+		// Fire the weapon (or allow firing animation to finish)
+		if p.fireTimer < 0 && (p.frame > 0 || p.triggered(weapon, firing)) {
 			if p.frame == 0 {
-				// Create a bullet
 				p.game.SoundEffect("laser0")
-				p.game.Fire(x, y-8)
+				weapon.Fire(p, x, y-8, p.chargeLevel(weapon))
+				p.charge = 0
 			}
 			p.frame = (p.frame + 1) % 3
-			p.fireTimer = ReloadTime
+			p.fireTimer = weapon.FireRate
+		}
+		if weapon.Charge > 0 && firing && p.frame == 0 && p.charge < weapon.Charge {
+			p.charge++
 		}
 
-		if p.game.enemy.Collision(x, y) {
+		if !p.HasPowerUp(PowerUpShield) && p.game.EnemyCollision(x, y) != nil {
 			p.game.SoundEffect("player_explode0")
 			p.game.Explosion(x, y, 1)
+			p.game.Particles(x, y, playerDebris)
+			p.game.effects.Shake(30, 10)
+			p.game.effects.HitStop(8)
+			p.game.effects.Flash(color.RGBA{0xc0, 0xc0, 0xc0, 0xc0}, 12)
 			p.alive = false
 			p.timer = 0
 			p.frame = 0
+			p.powerUps = [numPowerUps]int{}
+			p.charge = 0
 			if !Debug {
-				p.lives--
+				p.game.LoseLife(p)
 			}
 			// no player displayed during respawn time
 			p.sprite.SetImage(images["blank"])
 		}
 	} else {
-		// player not alive
-		if p.timer > RespawnTime {
+		// player not alive: respawn if there's any life left
+		if p.timer > RespawnTime && p.game.Lives(p) > 0 {
 			p.alive = true
 			p.timer = 0
-			p.sprite.MoveTo(240, 768)
+			p.sprite.MoveTo(p.spawnX, PlayerSpawnY)
 			// Ensure there are no rocks at the player's respawn position
-			p.game.ClearRocksForRespawn(240, 768)
+			p.game.ClearRocksForRespawn(p.spawnX, PlayerSpawnY)
 			p.sprite.Animate([]*ebiten.Image{images["blank"], p.images[p.direction][p.frame]}, nil, 2, true)
+			// spawn effect: the ship shrinks into place while fading in
+			p.sprite.SetScale(2, 2).SetAlpha(0)
+			p.effect = tween.NewParallel(
+				tween.ScaleTo(p.sprite, 1, 1, PlayerSpawnEffectTime, tween.OutBack),
+				tween.FadeTo(p.sprite, 1, PlayerSpawnEffectTime, tween.OutQuad),
+			)
+		}
+	}
+	for i := range p.powerUps {
+		if p.powerUps[i] > 0 {
+			p.powerUps[i]--
 		}
 	}
+	if p.effect != nil && p.effect.Update() {
+		p.effect = nil
+	}
 
 	if p.timer > InvulnerabilityTime {
 		p.sprite.Stop() // stop respawn animation (if started)
@@ -124,32 +174,82 @@ func (p *Player) Update() {
 	p.sprite.Update()
 }
 
-func (p *Player) Draw(screen *ebiten.Image) {
-	p.sprite.Draw(screen)
-	p.drawLives(screen)
-	p.drawScore(screen)
+// refreshImages is called after the images have been reloaded
+func (p *Player) refreshImages() {
+	for i := range p.images {
+		copy(p.images[i], imageList("player"+strconv.Itoa(i)+"%d", 3))
+	}
 }
 
-func (p *Player) Y() float64 {
-	return p.sprite.RawY()
+// IsOut returns true when the player has lost all their lives
+func (p *Player) IsOut() bool {
+	return !p.alive && p.game.Lives(p) == 0 && p.timer >= RespawnTime
+}
+
+// triggered returns true when the weapon should fire: as long as the button is held,
+// or when the button is released for a charged weapon
+func (p *Player) triggered(weapon Weapon, firing bool) bool {
+	if weapon.Charge > 0 {
+		return !firing && p.charge > 0
+	}
+	return firing
 }
 
-func (p *Player) drawLives(screen *ebiten.Image) {
-	// Display number of lives
-	for i := 0; i < p.lives; i++ {
-		p.op.GeoM.Reset()
-		p.op.GeoM.Translate(float64(i)*40+8, 4)
-		screen.DrawImage(p.life, p.op)
+// chargeLevel returns how much the weapon is charged, between 0 and 1
+func (p *Player) chargeLevel(weapon Weapon) float64 {
+	if weapon.Charge == 0 {
+		return 0
 	}
+	return float64(p.charge) / float64(weapon.Charge)
+}
+
+// SetWeapon changes the weapon of the player
+func (p *Player) SetWeapon(weapon *Weapon) {
+	p.weapon = weapon
+	p.charge = 0
 }
 
-func (p *Player) drawScore(screen *ebiten.Image) {
-	// Display score
-	score := strconv.Itoa(p.game.score)
-	for i := 0; i < len(score); i++ {
-		digit := string(score[len(score)-i-1])
-		p.op.GeoM.Reset()
-		p.op.GeoM.Translate(448-float64(i)*24, 5)
-		screen.DrawImage(images["digit"+digit], p.op)
+// PowerUp is called when the player picks up a power-up
+func (p *Player) PowerUp(kind PowerUpType) {
+	p.game.SoundEffect("level_clear")
+	if kind.Instant() {
+		p.game.SmartBomb(p)
+		return
 	}
+	p.powerUps[kind] = powerUpConfig.Duration
+	p.game.findAvailablePopup().StartText(p.sprite.X(lib.XCentre), p.sprite.Y(lib.YCentre)-40, strings.ToUpper(kind.String()))
+}
+
+// HasPowerUp returns true while the power-up is active
+func (p *Player) HasPowerUp(kind PowerUpType) bool {
+	return p.powerUps[kind] > 0
+}
+
+// PowerUpTime returns the time left for the power-up, between 0 and 1
+func (p *Player) PowerUpTime(kind PowerUpType) float64 {
+	if powerUpConfig == nil || powerUpConfig.Duration == 0 {
+		return 0
+	}
+	return float64(p.powerUps[kind]) / float64(powerUpConfig.Duration)
+}
+
+func (p *Player) Draw(screen *ebiten.Image) {
+	p.sprite.Draw(screen)
+	if p.alive && p.HasPowerUp(PowerUpShield) {
+		// the shield blinks when it's about to run out
+		if p.powerUps[PowerUpShield] > ShieldBlinkTime || (p.timer/4)%2 == 0 {
+			p.op.GeoM.Reset()
+			p.op.GeoM.Translate(-16, -16)
+			p.op.GeoM.Scale(2.5, 2.5)
+			p.op.GeoM.Translate(p.sprite.X(lib.XCentre), p.sprite.Y(lib.YCentre))
+			p.op.ColorScale.Reset()
+			p.op.ColorScale.Scale(0.4, 1, 0.6, 1)
+			p.op.ColorScale.ScaleAlpha(0.35)
+			screen.DrawImage(images["powerup"], p.op)
+		}
+	}
+}
+
+func (p *Player) Y() float64 {
+	return p.sprite.RawY()
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/tween"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Popup is a score value (or combo text) rising and fading out from where points were earned
+type Popup struct {
+	x      float64
+	y      float64
+	alpha  float64
+	score  string
+	text   string
+	effect tween.Tweener
+	op     *ebiten.DrawImageOptions
+}
+
+func NewPopup() *Popup {
+	return &Popup{
+		op: &ebiten.DrawImageOptions{},
+	}
+}
+
+// StartScore shows the points earned at this position
+func (p *Popup) StartScore(x, y float64, score int) {
+	p.score = strconv.Itoa(score)
+	p.text = ""
+	p.start(x, y)
+}
+
+// StartText shows a message at this position
+func (p *Popup) StartText(x, y float64, text string) {
+	p.score = ""
+	p.text = text
+	p.start(x, y)
+}
+
+func (p *Popup) start(x, y float64) {
+	p.x = x
+	p.y = y
+	p.alpha = 1
+	p.effect = tween.NewParallel(
+		tween.New(y, y-PopupRise, PopupTime, tween.OutQuad, func(value float64) {
+			p.y = value
+		}),
+		tween.NewSequence(
+			tween.Wait(PopupTime/2),
+			tween.New(1, 0, PopupTime/2, tween.InQuad, func(value float64) {
+				p.alpha = value
+			}),
+		),
+	)
+}
+
+func (p *Popup) IsDone() bool {
+	return p.effect == nil || p.effect.Done()
+}
+
+func (p *Popup) Update() {
+	if p.IsDone() {
+		return
+	}
+	p.effect.Update()
+}
+
+func (p *Popup) Draw(screen *ebiten.Image) {
+	if p.IsDone() {
+		return
+	}
+	if p.text != "" {
+		textFont.Draw(screen, p.text, p.x, p.y-PopupTextSize/2, &font.DrawOptions{
+			Align: font.AlignCentre,
+			Scale: PopupTextSize / float64(textFont.LineHeight()),
+			Alpha: p.alpha,
+		})
+		return
+	}
+	// digits are drawn at half size, centred on x
+	const digitWidth = 15
+	left := p.x - float64(len(p.score)*digitWidth)/2
+	for i := 0; i < len(p.score); i++ {
+		p.op.GeoM.Reset()
+		p.op.GeoM.Scale(0.5, 0.5)
+		p.op.GeoM.Translate(left+float64(i*digitWidth), p.y-digitWidth/2)
+		p.op.ColorScale.Reset()
+		p.op.ColorScale.ScaleAlpha(float32(p.alpha))
+		screen.DrawImage(images["digit"+string(p.score[i])], p.op)
+	}
+}
+
+// AddScoreAt adds the points to the player's score, and shows them at the position where they were earned
+func (g *Game) AddScoreAt(player *Player, score int, x, y float64) {
+	g.AddScore(player, score)
+	g.findAvailablePopup().StartScore(x, y, score)
+}
+
+// SegmentKilled counts the segments killed in quick succession, and shows the combo
+func (g *Game) SegmentKilled(x, y float64) {
+	if g.time-g.comboTime > ComboTime || g.time < g.comboTime {
+		g.combo = 0
+	}
+	g.combo++
+	g.comboTime = g.time
+	g.versusSegmentKilled()
+	if g.combo > 1 {
+		g.findAvailablePopup().StartText(x, y-PopupRise/2, "COMBO x"+strconv.Itoa(g.combo))
+	}
+}
+
+func (g *Game) findAvailablePopup() *Popup {
+	if g.replaying {
+		// already shown the first time the tick was simulated
+		return NewPopup()
+	}
+	for _, popup := range g.popups {
+		if popup.IsDone() {
+			return popup
+		}
+	}
+	popup := NewPopup()
+	g.popups = append(g.popups, popup)
+	return popup
+}
+
+func (g *Game) updatePopups() {
+	for _, popup := range g.popups {
+		popup.Update()
+	}
+}
+
+func (g *Game) drawPopups(screen *ebiten.Image) {
+	for _, popup := range g.popups {
+		popup.Draw(screen)
+	}
+}
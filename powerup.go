@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PowerUpType is the kind of bonus dropped by a totem
+type PowerUpType int
+
+// PowerUpType
+const (
+	PowerUpRapidFire PowerUpType = iota
+	PowerUpSpread
+	PowerUpPiercing
+	PowerUpShield
+	PowerUpSmartBomb
+	numPowerUps
+)
+
+var powerUpNames = [numPowerUps]string{"rapid", "spread", "pierce", "shield", "bomb"}
+
+var powerUpColours = [numPowerUps]color.RGBA{
+	{0xff, 0xd0, 0x40, 0xff},
+	{0x60, 0xc0, 0xff, 0xff},
+	{0xff, 0x60, 0xff, 0xff},
+	{0x60, 0xff, 0xa0, 0xff},
+	{0xff, 0x50, 0x40, 0xff},
+}
+
+// String representation of PowerUpType
+func (p PowerUpType) String() string {
+	if p < 0 || p >= numPowerUps {
+		return "unknown"
+	}
+	return powerUpNames[p]
+}
+
+// UnmarshalText reads a PowerUpType from its string representation
+func (p *PowerUpType) UnmarshalText(text []byte) error {
+	for i, name := range powerUpNames {
+		if name == string(text) {
+			*p = PowerUpType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown power-up %q", string(text))
+}
+
+// Instant returns true when the power-up takes effect once, instead of lasting for a while
+func (p PowerUpType) Instant() bool {
+	return p == PowerUpSmartBomb
+}
+
+// PowerUpWave is the drop table from a wave number onwards
+type PowerUpWave struct {
+	Wave   int                 `json:"wave"`
+	Chance float64             `json:"chance"` // chance of a drop when a totem is destroyed
+	Drops  map[PowerUpType]int `json:"drops"`  // relative weight of each power-up
+}
+
+// Pick returns the power-up matching value, a number between 0 and 1
+func (w PowerUpWave) Pick(value float64) (PowerUpType, bool) {
+	kinds := make([]PowerUpType, 0, len(w.Drops))
+	total := 0
+	for kind, weight := range w.Drops {
+		if weight > 0 {
+			kinds = append(kinds, kind)
+			total += weight
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	// map order is random: sort so the same value always picks the same power-up
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	target := int(value * float64(total))
+	for _, kind := range kinds {
+		target -= w.Drops[kind]
+		if target < 0 {
+			return kind, true
+		}
+	}
+	return kinds[len(kinds)-1], true
+}
+
+// PowerUpConfig defines which power-ups can drop during each wave, and how long they last
+type PowerUpConfig struct {
+	Duration int           `json:"duration"` // in ticks
+	Waves    []PowerUpWave `json:"waves"`
+}
+
+// LoadPowerUpConfig reads the JSON configuration
+func LoadPowerUpConfig(data []byte) (*PowerUpConfig, error) {
+	config := &PowerUpConfig{}
+	err := json.Unmarshal(data, config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Duration <= 0 {
+		config.Duration = PowerUpDuration
+	}
+	sort.SliceStable(config.Waves, func(i, j int) bool { return config.Waves[i].Wave < config.Waves[j].Wave })
+	return config, nil
+}
+
+// ForWave returns the drop table for this wave: the last one starting at or before it
+func (c *PowerUpConfig) ForWave(wave int) (PowerUpWave, bool) {
+	found := false
+	current := PowerUpWave{}
+	for _, table := range c.Waves {
+		if table.Wave > wave {
+			break
+		}
+		current = table
+		found = true
+	}
+	return current, found
+}
+
+// PowerUp is a bonus falling down from a destroyed totem, until the player picks it up
+type PowerUp struct {
+	game   *Game
+	sprite *lib.Sprite
+	kind   PowerUpType
+	done   bool
+}
+
+func NewPowerUp(game *Game) *PowerUp {
+	return &PowerUp{
+		game:   game,
+		sprite: lib.NewSprite(lib.XCentre, lib.YCentre).SetImage(images["powerup"]),
+		done:   true,
+	}
+}
+
+func (p *PowerUp) Start(x, y float64, kind PowerUpType) {
+	p.done = false
+	p.kind = kind
+	p.sprite.MoveTo(x, y).SetTint(powerUpColours[kind])
+}
+
+func (p *PowerUp) IsDone() bool {
+	return p.done
+}
+
+// refreshImages is called after the images have been reloaded
+func (p *PowerUp) refreshImages() {
+	p.sprite.SetImage(images["powerup"])
+}
+
+func (p *PowerUp) Update() {
+	if p.done {
+		return
+	}
+	p.sprite.Move(0, PowerUpFallSpeed)
+	if p.sprite.Y(lib.YCentre) > WindowHeight+16 {
+		p.done = true
+		return
+	}
+	for _, player := range p.game.players {
+		if player.alive && p.sprite.CollidePoint(player.sprite.X(lib.XCentre), player.sprite.Y(lib.YCentre)) {
+			p.done = true
+			player.PowerUp(p.kind)
+			return
+		}
+	}
+}
+
+func (p *PowerUp) Draw(screen *ebiten.Image) {
+	if p.done {
+		return
+	}
+	p.sprite.Draw(screen)
+	textFont.Draw(screen, p.kind.String()[:1], p.sprite.X(lib.XCentre), p.sprite.Y(lib.YCentre)-float64(textFont.LineHeight())*0.3, &font.DrawOptions{
+		Align:  font.AlignCentre,
+		Colour: color.RGBA{0x20, 0x20, 0x30, 0xff},
+		Scale:  0.6,
+	})
+}
+
+// DropPowerUp may release a power-up from a destroyed totem, depending on the current wave
+func (g *Game) DropPowerUp(x, y float64) {
+	if powerUpConfig == nil {
+		return
+	}
+	table, found := powerUpConfig.ForWave(g.wave)
+	if !found || g.random.Float64() >= table.Chance {
+		return
+	}
+	kind, ok := table.Pick(g.random.Float64())
+	if !ok {
+		return
+	}
+	g.findAvailablePowerUp().Start(x, y, kind)
+}
+
+// SmartBomb destroys every enemy, and every myriapod segment in the player zone. The player gets the points.
+func (g *Game) SmartBomb(player *Player) {
+	g.SoundEffect("meanie_explode0")
+	g.effects.Flash(color.RGBA{0xe0, 0xe0, 0xe0, 0xe0}, 16)
+	g.effects.Shake(20, 8)
+	for _, enemy := range g.enemies {
+		if !enemy.IsInactive() {
+			x, y := enemy.Position()
+			enemy.Destroy()
+			g.AddScoreAt(player, enemy.Score(), x, y)
+			g.Explosion(x, y, 2)
+		}
+	}
+	remaining := g.segments[:0]
+	for _, segment := range g.segments {
+		if segment.Y() >= PlayerMinY {
+			g.AddScoreAt(player, 10, segment.posX, segment.posY)
+			g.Explosion(segment.posX, segment.posY, 2)
+			g.Particles(segment.posX, segment.posY, segmentSplat)
+			continue
+		}
+		remaining = append(remaining, segment)
+	}
+	g.segments = remaining
+}
+
+func (g *Game) findAvailablePowerUp() *PowerUp {
+	for _, powerUp := range g.powerUps {
+		if powerUp.IsDone() {
+			return powerUp
+		}
+	}
+	powerUp := NewPowerUp(g)
+	g.powerUps = append(g.powerUps, powerUp)
+	return powerUp
+}
+
+func (g *Game) updatePowerUps() {
+	for _, powerUp := range g.powerUps {
+		powerUp.Update()
+	}
+}
+
+func (g *Game) drawPowerUps(screen *ebiten.Image) {
+	for _, powerUp := range g.powerUps {
+		powerUp.Draw(screen)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPowerUpConfig(t *testing.T) {
+	data, err := embeddedFiles.ReadFile("data/powerups.json")
+	require.NoError(t, err)
+	config, err := LoadPowerUpConfig(data)
+	require.NoError(t, err)
+	assert.Greater(t, config.Duration, 0)
+	require.NotEmpty(t, config.Waves)
+	assert.Equal(t, 0, config.Waves[0].Wave)
+}
+
+func TestLoadPowerUpConfigErrors(t *testing.T) {
+	_, err := LoadPowerUpConfig([]byte(`{"waves": [{"drops": {"laser": 1}}]}`))
+	assert.Error(t, err)
+
+	config, err := LoadPowerUpConfig([]byte(`{"waves": []}`))
+	require.NoError(t, err)
+	assert.Equal(t, PowerUpDuration, config.Duration)
+}
+
+func TestPowerUpForWave(t *testing.T) {
+	config, err := LoadPowerUpConfig([]byte(`{"waves": [
+		{"wave": 4, "chance": 0.5, "drops": {"bomb": 1}},
+		{"wave": 1, "chance": 0.1, "drops": {"rapid": 1}}
+	]}`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		wave   int
+		found  bool
+		chance float64
+	}{
+		{0, false, 0},
+		{1, true, 0.1},
+		{3, true, 0.1},
+		{4, true, 0.5},
+		{20, true, 0.5},
+	}
+	for _, testCase := range testCases {
+		table, found := config.ForWave(testCase.wave)
+		assert.Equal(t, testCase.found, found, "wave %d", testCase.wave)
+		assert.Equal(t, testCase.chance, table.Chance, "wave %d", testCase.wave)
+	}
+}
+
+func TestPowerUpPick(t *testing.T) {
+	table := PowerUpWave{Drops: map[PowerUpType]int{
+		PowerUpShield:    1,
+		PowerUpRapidFire: 2,
+		PowerUpSpread:    0,
+		PowerUpSmartBomb: 1,
+	}}
+	testCases := []struct {
+		value    float64
+		expected PowerUpType
+	}{
+		{0, PowerUpRapidFire},
+		{0.49, PowerUpRapidFire},
+		{0.5, PowerUpShield},
+		{0.74, PowerUpShield},
+		{0.75, PowerUpSmartBomb},
+		{0.999, PowerUpSmartBomb},
+	}
+	for _, testCase := range testCases {
+		kind, ok := table.Pick(testCase.value)
+		assert.True(t, ok)
+		assert.Equal(t, testCase.expected, kind, "value %f", testCase.value)
+	}
+
+	_, ok := PowerUpWave{}.Pick(0.5)
+	assert.False(t, ok)
+}
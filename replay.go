@@ -0,0 +1,314 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/leaderboard"
+	"github.com/cavern/creativeprojects/myriapod/lib/particle"
+	"github.com/cavern/creativeprojects/myriapod/lib/random"
+	"github.com/cavern/creativeprojects/myriapod/lib/replay"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+)
+
+// newRecording starts the replay of a game, with all the options needed to play it again
+func (g *Game) newRecording(players int, alternate bool) *replay.Replay {
+	return &replay.Replay{
+		Version:        replay.Version,
+		Seed:           g.seed,
+		Players:        players,
+		Alternate:      alternate,
+		SharedLives:    g.sharedLives,
+		Weapon:         g.weapon.Name,
+		ExtraLifeEvery: g.extraLifeRule.Every,
+		ExtraLifeAt:    g.extraLifeRule.Scores,
+	}
+}
+
+// record the inputs of the tick about to be played, and the hash of the state at the checkpoints
+func (g *Game) record() {
+	if g.recording == nil {
+		return
+	}
+	if replay.IsCheckpoint(g.recording.Frames) {
+		g.recording.Checkpoint(g.StateHash())
+	}
+	inputs := make([]rollback.Input, len(g.players))
+	for i, player := range g.players {
+		inputs[i] = readInput(player.controls)
+	}
+	g.recording.Record(inputs)
+}
+
+// finishRecording adds the result to the replay, and submits it to the leaderboard
+func (g *Game) finishRecording() {
+	if g.recording == nil {
+		return
+	}
+	r := g.recording
+	g.recording = nil
+	for _, player := range g.allPlayers() {
+		r.Scores = append(r.Scores, player.score)
+	}
+	r.Wave = g.wave
+	if g.replayFile != "" {
+		err := saveReplay(g.replayFile, r)
+		if err != nil {
+			log.Print(err)
+		}
+	}
+	if g.leaderboard == "" {
+		return
+	}
+	g.submission = "SUBMITTING SCORE..."
+	submitted := g.submitted
+	go func() {
+		entry, err := leaderboard.Submit(g.leaderboard, leaderboard.Submission{Name: g.playerName, Replay: r})
+		if err != nil {
+			log.Print(err)
+			submitted <- "SCORE NOT SUBMITTED"
+			return
+		}
+		submitted <- "LEADERBOARD RANK " + strconv.Itoa(entry.Rank)
+	}()
+}
+
+// updateSubmission shows the result of the submission once received
+func (g *Game) updateSubmission() {
+	select {
+	case submission := <-g.submitted:
+		g.submission = submission
+	default:
+	}
+}
+
+// NewHeadlessGame creates a game without display nor sound, to play replays again
+func NewHeadlessGame() *Game {
+	g := &Game{
+		state:         StateMenu,
+		space:         lib.NewSprite(lib.XLeft, lib.YTop),
+		particles:     particle.NewSystem(MaxParticles),
+		effects:       NewEffects(),
+		extraLifeRule: DefaultExtraLifeRule(),
+		weapon:        &Cannon,
+		headless:      true,
+		// nothing to show or play: a replay is played the same way as a tick simulated again after a rollback
+		replaying: true,
+	}
+	g.random, g.randomSource = random.New(0)
+	g.hud = NewHUD(g)
+	g.effects.SetMuted(true)
+	return g.Initialize()
+}
+
+// ReplayResult is the end of a game: claimed in a replay, or played again from it
+type ReplayResult struct {
+	Scores   []int
+	Wave     int
+	Frames   int
+	GameOver bool
+}
+
+// ReplayReport compares the result claimed in a replay with the result of the game played again
+type ReplayReport struct {
+	Claimed     ReplayResult
+	Played      ReplayResult
+	Checkpoints int // hashes of the state checked
+	Tampered    int // first frame where the state is not the one recorded, -1 if none
+}
+
+// Problems lists the differences between the claimed and the played results
+func (r ReplayReport) Problems() []string {
+	problems := make([]string, 0)
+	if r.Tampered >= 0 {
+		problems = append(problems, fmt.Sprintf("state differs from the recording at frame %d", r.Tampered))
+	}
+	if !r.Played.GameOver {
+		problems = append(problems, fmt.Sprintf("game not over after frame %d", r.Played.Frames))
+	}
+	if r.Played.Frames != r.Claimed.Frames {
+		problems = append(problems, fmt.Sprintf("%d frames played, claimed %d", r.Played.Frames, r.Claimed.Frames))
+	}
+	if r.Played.Wave != r.Claimed.Wave {
+		problems = append(problems, fmt.Sprintf("wave %d, claimed %d", r.Played.Wave, r.Claimed.Wave))
+	}
+	if !slices.Equal(r.Played.Scores, r.Claimed.Scores) {
+		problems = append(problems, fmt.Sprintf("scores %v, claimed %v", r.Played.Scores, r.Claimed.Scores))
+	}
+	return problems
+}
+
+// OK returns true when the game played again gives the claimed result
+func (r ReplayReport) OK() bool {
+	return len(r.Problems()) == 0
+}
+
+// Write the report in a human readable form
+func (r ReplayReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "frames: %d, claimed %d\n", r.Played.Frames, r.Claimed.Frames)
+	fmt.Fprintf(w, "wave: %d, claimed %d\n", r.Played.Wave, r.Claimed.Wave)
+	fmt.Fprintf(w, "scores: %v, claimed %v\n", r.Played.Scores, r.Claimed.Scores)
+	fmt.Fprintf(w, "hashes: %d checked\n", r.Checkpoints)
+	for _, problem := range r.Problems() {
+		fmt.Fprintf(w, "mismatch: %s\n", problem)
+	}
+	if r.OK() {
+		fmt.Fprintln(w, "replay OK")
+	}
+}
+
+// VerifyReplay plays the replay again without display nor sound, and compares the result with the one claimed.
+// It returns an error when the replay can't be played.
+func VerifyReplay(r *replay.Replay) (ReplayReport, error) {
+	err := r.Validate()
+	if err != nil {
+		return ReplayReport{}, err
+	}
+	weapon, found := Weapons[r.Weapon]
+	if !found {
+		return ReplayReport{}, fmt.Errorf("unknown weapon %q", r.Weapon)
+	}
+	g := NewHeadlessGame()
+	g.weapon = weapon
+	g.sharedLives = r.SharedLives
+	g.extraLifeRule.Every = r.ExtraLifeEvery
+	g.extraLifeRule.Scores = r.ExtraLifeAt
+	g.start(r.Players, r.Alternate, r.Seed)
+
+	report := ReplayReport{
+		Claimed: ReplayResult{
+			Scores:   r.Scores,
+			Wave:     r.Wave,
+			Frames:   r.Frames,
+			GameOver: true,
+		},
+		Tampered: -1,
+	}
+	checker := r.Checker()
+	reader := r.Reader()
+	for g.state != StateGameOver {
+		inputs, ok := reader.Next()
+		if !ok {
+			break
+		}
+		if replay.IsCheckpoint(report.Played.Frames) && !checker.Check(g.StateHash()) && report.Tampered < 0 {
+			report.Tampered = report.Played.Frames
+		}
+		copy(g.netInputs[:], inputs)
+		g.step()
+		report.Played.Frames++
+		if g.state == StateNextPlayer {
+			// no one to wait for
+			g.state = StatePlaying
+		}
+	}
+	report.Checkpoints = checker.Checked()
+	for _, player := range g.allPlayers() {
+		report.Played.Scores = append(report.Played.Scores, player.score)
+	}
+	report.Played.Wave = g.wave
+	report.Played.GameOver = g.state == StateGameOver
+	return report, nil
+}
+
+// validateReplay is the leaderboard validator: the score is only accepted when the replay gives the same result
+func validateReplay(r *replay.Replay) error {
+	report, err := VerifyReplay(r)
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return errors.New(strings.Join(report.Problems(), ", "))
+	}
+	return nil
+}
+
+// saveReplay writes the replay in a file
+func saveReplay(filename string, r *replay.Replay) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = r.Write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// verifyReplayCommand plays the replay in the file again, and reports whether it gives the claimed result.
+// It returns the exit code.
+func verifyReplayCommand(filename string) int {
+	file, err := os.Open(filename)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	r, err := replay.Read(file)
+	file.Close()
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	err = loadResources()
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	report, err := VerifyReplay(r)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	report.Write(os.Stdout)
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// leaderboardServerCommand runs the leaderboard HTTP service, keeping its files in dir. It returns the exit code.
+func leaderboardServerCommand(addr, dir string) int {
+	if addr == "" {
+		addr = LeaderboardAddr
+	}
+	if dir == "" {
+		dir = LeaderboardDir
+	}
+	err := loadResources()
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	board, err := leaderboard.Open(dir, validateReplay)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	log.Printf("leaderboard listening on %s, saved in %s", addr, dir)
+	err = http.ListenAndServe(addr, board.Handler())
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
+
+// defaultPlayerName is the name on the leaderboard when none is given
+func defaultPlayerName() string {
+	for _, variable := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(variable); name != "" {
+			return name
+		}
+	}
+	return "PLAYER"
+}
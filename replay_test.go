@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/replay"
+	"github.com/cavern/creativeprojects/myriapod/lib/rollback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecording(t *testing.T) {
+	game := newNetplayGame()
+	game.headless = false
+	game.seed = 42
+	game.weapon = &Cannon
+	game.extraLifeRule = DefaultExtraLifeRule()
+	game.recording = game.newRecording(2, false)
+
+	game.netInputs[0] = rollback.InputLeft | rollback.InputFire
+	game.record()
+	game.record()
+	game.netInputs[1] = rollback.InputUp
+	game.record()
+
+	game.players[0].score = 120
+	game.players[1].score = 30
+	game.wave = 4
+	r := game.recording
+	game.finishRecording()
+	assert.Nil(t, game.recording)
+
+	require.NoError(t, r.Validate())
+	assert.Equal(t, int64(42), r.Seed)
+	assert.Equal(t, Cannon.Name, r.Weapon)
+	assert.Equal(t, 3, r.Frames)
+	assert.Equal(t, []int{120, 30}, r.Scores)
+	assert.Equal(t, 4, r.Wave)
+	assert.Equal(t, 150, r.Score())
+	// the state is hashed at the start of the game
+	require.Len(t, r.Hashes, 1)
+
+	reader := r.Reader()
+	for _, expected := range [][]rollback.Input{
+		{rollback.InputLeft | rollback.InputFire, 0},
+		{rollback.InputLeft | rollback.InputFire, 0},
+		{rollback.InputLeft | rollback.InputFire, rollback.InputUp},
+	} {
+		inputs, ok := reader.Next()
+		require.True(t, ok)
+		assert.Equal(t, expected, inputs)
+	}
+	_, ok := reader.Next()
+	assert.False(t, ok)
+}
+
+func TestReplayReport(t *testing.T) {
+	claimed := ReplayResult{Scores: []int{120, 30}, Wave: 4, Frames: 600, GameOver: true}
+	testData := []struct {
+		name     string
+		played   ReplayResult
+		tampered int
+		problems int
+	}{
+		{"same", ReplayResult{Scores: []int{120, 30}, Wave: 4, Frames: 600, GameOver: true}, -1, 0},
+		{"tampered", ReplayResult{Scores: []int{120, 30}, Wave: 4, Frames: 600, GameOver: true}, 120, 1},
+		{"score", ReplayResult{Scores: []int{120, 20}, Wave: 4, Frames: 600, GameOver: true}, -1, 1},
+		{"game over early", ReplayResult{Scores: []int{120, 30}, Wave: 4, Frames: 540, GameOver: true}, -1, 1},
+		{"not over", ReplayResult{Scores: []int{110, 30}, Wave: 3, Frames: 600}, 300, 4},
+	}
+
+	for _, testItem := range testData {
+		t.Run(testItem.name, func(t *testing.T) {
+			report := ReplayReport{Claimed: claimed, Played: testItem.played, Tampered: testItem.tampered}
+			assert.Len(t, report.Problems(), testItem.problems)
+			assert.Equal(t, testItem.problems == 0, report.OK())
+		})
+	}
+}
+
+// playRecordedGame plays a game with the same inputs as a live one, up to game over, and returns its replay
+func playRecordedGame(t *testing.T, players int, seed int64) *replay.Replay {
+	t.Helper()
+	game := newHeadlessTestGame(t, players, seed)
+	// a live game: everything is played, as opposed to a replay
+	game.replaying = false
+	game.recording = game.newRecording(players, false)
+	r := game.recording
+	for tick := 0; game.state != StateGameOver; tick++ {
+		require.Less(t, tick, replay.MaxFrames, "the game never ends")
+		for player := range players {
+			game.netInputs[player] = netplayTestInput(player, tick)
+		}
+		game.record()
+		game.step()
+	}
+	require.Nil(t, game.recording, "the recording is finished at game over")
+	return r
+}
+
+func TestReplayPlayedAgain(t *testing.T) {
+	r := playRecordedGame(t, 2, 7)
+	// through a file
+	buffer := &bytes.Buffer{}
+	require.NoError(t, r.Write(buffer))
+	r, err := replay.Read(buffer)
+	require.NoError(t, err)
+
+	report, err := VerifyReplay(r)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), report.Problems())
+	assert.Equal(t, r.Frames, report.Played.Frames)
+	assert.Equal(t, r.Scores, report.Played.Scores)
+	assert.Equal(t, len(r.Hashes), report.Checkpoints)
+	assert.Equal(t, -1, report.Tampered)
+}
+
+func TestTamperedReplay(t *testing.T) {
+	r := playRecordedGame(t, 1, 7)
+	// the player moves and fires at the start, instead of waiting
+	first := &r.Inputs[0][0]
+	require.Zero(t, first.Input)
+	first.Input = rollback.InputLeft | rollback.InputFire
+
+	report, err := VerifyReplay(r)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	// the state at the start of the game is the same: the hash chain breaks at the next checkpoint or later
+	assert.GreaterOrEqual(t, report.Tampered, replay.HashInterval)
+}
@@ -10,11 +10,13 @@ import (
 
 	_ "image/png"
 
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
 )
 
-//go:embed images sounds music
+//go:embed images sounds music data
 var embeddedFiles embed.FS
 
 func loadImages() (map[string]*ebiten.Image, error) {
@@ -22,26 +24,52 @@ func loadImages() (map[string]*ebiten.Image, error) {
 	if err != nil {
 		return nil, err
 	}
-	imagesMap := make(map[string]*ebiten.Image, len(imageNames))
+	decoded := make(map[string]image.Image, len(imageNames))
 	for _, imageName := range imageNames {
-		file, err := embeddedFiles.Open(imageName)
+		img, err := readImage(embeddedFiles, imageName)
 		if err != nil {
-			return imagesMap, fmt.Errorf("%s: %w", imageName, err)
+			return nil, err
 		}
-		defer file.Close()
-		img, _, err := image.Decode(file)
-		if err != nil {
-			return imagesMap, fmt.Errorf("%s: %w", imageName, err)
-		}
-		img2 := ebiten.NewImageFromImage(img)
-		if err != nil {
-			return imagesMap, fmt.Errorf("%s: %w", imageName, err)
-		}
-		imageName = path.Base(imageName)
-		imageName = strings.TrimSuffix(imageName, path.Ext(imageName))
-		imagesMap[imageName] = img2
+		decoded[assetName(imageName)] = img
+	}
+	// all the images are packed into a few large textures to limit texture switches when drawing
+	atlas, err := lib.NewAtlas(decoded, AtlasSize)
+	if err != nil {
+		return nil, err
+	}
+	return atlas.Images(), nil
+}
+
+// loadAnimations reads the clips definition. Frames are taken from the images already loaded.
+func loadAnimations() (*lib.AnimationSet, error) {
+	return lib.LoadAnimationSet(embeddedFiles, "data/animations.json", images)
+}
+
+// loadFont reads the bitmap font description. The glyph sheet is taken from the images already loaded.
+func loadFont() (*font.Font, error) {
+	data, err := fs.ReadFile(embeddedFiles, "data/font.json")
+	if err != nil {
+		return nil, err
+	}
+	return font.Load(data, images)
+}
+
+// loadPowerUps reads which power-ups drop during each wave
+func loadPowerUps() (*PowerUpConfig, error) {
+	data, err := fs.ReadFile(embeddedFiles, "data/powerups.json")
+	if err != nil {
+		return nil, err
+	}
+	return LoadPowerUpConfig(data)
+}
+
+// loadBosses reads the boss waves
+func loadBosses() (*BossConfig, error) {
+	data, err := fs.ReadFile(embeddedFiles, "data/bosses.json")
+	if err != nil {
+		return nil, err
 	}
-	return imagesMap, nil
+	return LoadBossConfig(data)
 }
 
 func loadSounds() (map[string][]byte, error) {
@@ -51,22 +79,59 @@ func loadSounds() (map[string][]byte, error) {
 	}
 	soundsMap := make(map[string][]byte, len(soundNames))
 	for _, soundName := range soundNames {
-		file, err := embeddedFiles.Open(soundName)
+		buf, err := readSound(embeddedFiles, soundName)
 		if err != nil {
-			return soundsMap, fmt.Errorf("%s: %w", soundName, err)
+			return soundsMap, err
 		}
-		snd, err := vorbis.DecodeWithSampleRate(SampleRate, file)
-		if err != nil {
-			return soundsMap, fmt.Errorf("%s: %w", soundName, err)
-		}
-		buf := make([]byte, snd.Length())
-		_, err = snd.Read(buf)
-		if err != nil {
-			return soundsMap, fmt.Errorf("%s: %w", soundName, err)
-		}
-		soundName = path.Base(soundName)
-		soundName = strings.TrimSuffix(soundName, path.Ext(soundName))
-		soundsMap[soundName] = buf
+		soundsMap[assetName(soundName)] = buf
 	}
 	return soundsMap, nil
 }
+
+// readImage decodes a PNG file from the file system
+func readImage(fsys fs.FS, imageName string) (image.Image, error) {
+	file, err := fsys.Open(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", imageName, err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", imageName, err)
+	}
+	return img, nil
+}
+
+// readSound decodes an OGG file from the file system into raw PCM bytes
+func readSound(fsys fs.FS, soundName string) ([]byte, error) {
+	file, err := fsys.Open(soundName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", soundName, err)
+	}
+	defer file.Close()
+	snd, err := vorbis.DecodeWithSampleRate(SampleRate, file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", soundName, err)
+	}
+	buf := make([]byte, snd.Length())
+	_, err = snd.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", soundName, err)
+	}
+	return buf, nil
+}
+
+// assetName returns the key used in the images and sounds maps: file name without the extension
+func assetName(fileName string) string {
+	fileName = path.Base(fileName)
+	return strings.TrimSuffix(fileName, path.Ext(fileName))
+}
+
+// imageList returns count images whose names are built from format and the index, e.g. imageList("exp0%d", 8)
+func imageList(format string, count int) []*ebiten.Image {
+	list := make([]*ebiten.Image, count)
+	for i := range list {
+		list[i] = images[fmt.Sprintf(format, i)]
+	}
+	return list
+}
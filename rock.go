@@ -1,8 +1,8 @@
 package main
 
 import (
+	"image/color"
 	"log"
-	"math/rand"
 	"strconv"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
@@ -14,6 +14,7 @@ type Rock struct {
 	sprite     *lib.Sprite
 	timer      int
 	isTotem    bool
+	poisoned   bool
 	rockType   int
 	health     int
 	showHealth int
@@ -27,7 +28,7 @@ func NewRock(game *Game, cellX, cellY int, isTotem bool) *Rock {
 	health := 5
 	showHealth := 5
 	if !isTotem {
-		health = rand.Intn(2) + 3
+		health = game.random.Intn(2) + 3
 		showHealth = 1
 	}
 	posX, posY := CellToPos(cellX, cellY, 0, 0)
@@ -36,7 +37,7 @@ func NewRock(game *Game, cellX, cellY int, isTotem bool) *Rock {
 		sprite:     lib.NewSprite(lib.XCentre, lib.YCentre).MoveTo(posX, posY),
 		timer:      1,
 		isTotem:    isTotem,
-		rockType:   rand.Intn(4),
+		rockType:   game.random.Intn(4),
 		health:     health,
 		showHealth: showHealth,
 		cellX:      cellX,
@@ -46,18 +47,25 @@ func NewRock(game *Game, cellX, cellY int, isTotem bool) *Rock {
 	}
 }
 
-func (r *Rock) Damage(amount int, damagedByBullet bool) bool {
+// Damage the rock. by is the player who shot it, or nil when destroyed by a segment or cleared for a respawn.
+func (r *Rock) Damage(amount int, by *Player) bool {
 	// Damage can occur by being hit by bullets, or by being destroyed by a segment, or by being cleared from the
 	// player's respawn location. Points can be earned by hitting special "totem" rocks, which have 5 health, but
 	// this should only happen when they are hit by a bullet.
-	if damagedByBullet && r.health == 5 {
+	x, y := r.sprite.X(lib.XCentre), r.sprite.Y(lib.YCentre)
+	if by != nil && r.health == 5 {
 		r.game.SoundEffect("totem_destroy0")
-		r.game.AddScore(100)
+		r.game.AddScoreAt(by, 100, x, y)
+		r.game.DropPowerUp(x, y)
+		r.game.Particles(x, y, totemBurst)
+		r.game.effects.Shake(12, 4)
+		r.game.effects.HitStop(3)
+		r.game.effects.Flash(color.RGBA{0x60, 0x54, 0x30, 0x60}, 8)
 	} else {
 		if amount > r.health-1 {
 			r.game.SoundEffect("rock_destroy0")
 		} else {
-			r.game.SoundEffect("hit" + strconv.Itoa(rand.Intn(4)))
+			r.game.SoundEffect("hit" + strconv.Itoa(r.game.random.Intn(4)))
 		}
 	}
 
@@ -65,14 +73,27 @@ func (r *Rock) Damage(amount int, damagedByBullet bool) bool {
 	if r.health == 5 {
 		expType = 2
 	}
-	r.game.Explosion(r.sprite.X(lib.XCentre), r.sprite.Y(lib.YCentre), expType)
+	r.game.Explosion(x, y, expType)
 	r.health -= amount
 	r.showHealth = r.health
+	if r.health < 1 {
+		r.game.Particles(x, y, rockDebris)
+	}
 
 	// Return false if we've lost all our health
 	return r.health < 1
 }
 
+// Poison the rock: a myriapod head touching it will dive straight down to the player zone
+func (r *Rock) Poison() {
+	r.poisoned = true
+}
+
+// IsPoisoned returns true when the rock has been poisoned
+func (r *Rock) IsPoisoned() bool {
+	return r.poisoned
+}
+
 func (r *Rock) Update() {
 	r.timer++
 	// Every other frame, update the growing animation
@@ -81,16 +102,16 @@ func (r *Rock) Update() {
 	}
 	colour := max(r.game.wave, 0) % 3
 	health := max(r.showHealth-1, 0)
-	image := "rock" +
-		strconv.Itoa(colour) +
-		strconv.Itoa(r.rockType) +
-		strconv.Itoa(health)
-	r.sprite.SetImage(images[image])
+	if r.poisoned {
+		r.sprite.SetImage(poisonImages[r.rockType][health])
+		return
+	}
+	r.sprite.SetImage(rockImages[colour][r.rockType][health])
 }
 
 func (r *Rock) Draw(screen *ebiten.Image) {
 	if !r.sprite.HasImage() {
-		log.Printf("No image for rock: totem=%v, type=%d, health=%d", r.isTotem, r.rockType, r.health)
+		log.Printf("No image for rock: totem=%v, poisoned=%v, type=%d, health=%d", r.isTotem, r.poisoned, r.rockType, r.health)
 		return
 	}
 	r.sprite.Draw(screen)
@@ -2,8 +2,6 @@ package main
 
 import (
 	"log"
-	"strconv"
-	"strings"
 
 	"github.com/cavern/creativeprojects/myriapod/lib"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -20,6 +18,9 @@ type Segment struct {
 	health             int
 	fast               bool
 	head               bool
+	diving             bool // poisoned: going straight down to the player zone
+	boss               bool
+	armoured           bool
 	inEdge             Direction
 	outEdge            Direction
 	disallowDirection  Direction
@@ -46,9 +47,15 @@ func NewSegment(game *Game, cx, cy, health int, fast, head bool) *Segment {
 	}
 }
 
-func (s *Segment) Collision(x, y float64) bool {
+// Deflects returns true when the coordinates hit the armoured front of the segment, for a bullet moving by (dx, dy)
+func (s *Segment) Deflects(x, y, dx, dy float64) bool {
+	return s.armoured && s.sprite.CollidePoint(x, y) && armourDeflects(s.direction, dx, dy)
+}
+
+// Collision returns true when the coordinates hit the segment, which then loses health
+func (s *Segment) Collision(x, y float64, damage int) bool {
 	if s.sprite.CollidePoint(x, y) {
-		s.health--
+		s.health -= damage
 		return true
 	}
 	return false
@@ -57,27 +64,7 @@ func (s *Segment) Collision(x, y float64) bool {
 func (s *Segment) Update() {
 	s.update()
 	s.sprite.MoveTo(s.posX, s.posY)
-
-	imageName := &strings.Builder{}
-	imageName.WriteString("seg")
-	if s.fast {
-		imageName.WriteByte('1')
-	} else {
-		imageName.WriteByte('0')
-	}
-	if s.health == 2 {
-		imageName.WriteByte('1')
-	} else {
-		imageName.WriteByte('0')
-	}
-	if s.head {
-		imageName.WriteByte('1')
-	} else {
-		imageName.WriteByte('0')
-	}
-	imageName.WriteString(strconv.Itoa(int(s.direction)))
-	imageName.WriteString(strconv.Itoa(s.legFrame))
-	s.sprite.SetImage(images[imageName.String()])
+	s.sprite.SetImage(segmentImages[boolIndex(s.fast)][boolIndex(s.health >= 2)][boolIndex(s.head)][s.direction][s.legFrame])
 	s.sprite.Update()
 }
 
@@ -108,12 +95,17 @@ func (s *Segment) update() {
 		// Once it reaches row 18, it starts moving down again, so that it remains a threat to the player.
 		// During the title screen, we allow segments to go all the way back up to the top of the screen.
 		tempY := 0
-		if s.game.player != nil {
+		if len(s.game.players) > 0 {
 			tempY = 18
 		}
 		if s.cy == tempY {
 			s.disallowDirection = DirectionUp
 		}
+		// A poisoned myriapod stops diving once it's reached the player zone
+		if s.diving && s.cy >= PoisonDiveRow {
+			s.diving = false
+			s.disallowDirection = DirectionUp
+		}
 		if s.cy == NumGridRows-1 {
 			s.disallowDirection = DirectionDown
 		}
@@ -142,9 +134,18 @@ func (s *Segment) update() {
 		newCellX := s.cx + DX[s.outEdge]
 		newCellY := s.cy + DY[s.outEdge]
 
+		// A head touching a poisoned rock makes the myriapod dive. The rest of the body dives when it reaches
+		// the same cell, as the rock will be gone by then.
+		if s.head && s.game.IsPoisoned(newCellX, newCellY) {
+			s.game.AddPoisonTrail(newCellX, newCellY)
+		}
+		if s.game.IsPoisonTrail(newCellX, newCellY) {
+			s.dive()
+		}
+
 		// Destroy any rock that might be in the new cell
 		if newCellX >= 0 && newCellX < NumGridCols {
-			s.game.Damage(newCellX, newCellY, 5, false)
+			s.game.Damage(newCellX, newCellY, 5, nil)
 		}
 
 		// Set new cell as occupied. It's a case of whichever segment is processed first, gets first dibs on a cell
@@ -194,7 +195,7 @@ func (s *Segment) update() {
 
 	// Finally, we can calculate the segment's position on the screen.
 	s.posX, s.posY = CellToPos(s.cx, s.cy, offsetX, offsetY)
-	if s.head {
+	if s.head && Debug {
 		log.Printf("cell x=%d, y=%d; offset x=%d, y=%d -> pos x=%f, y=%f", s.cx, s.cy, offsetX, offsetY, s.posX, s.posY)
 	}
 
@@ -227,6 +228,15 @@ func (s *Segment) update() {
 	s.legFrame = phase / 4 // 16 phase cycle, 4 frames of animation
 }
 
+// dive sends the segment straight down, until it reaches the player zone
+func (s *Segment) dive() {
+	if s.cy+1 >= PoisonDiveRow {
+		return
+	}
+	s.diving = true
+	s.disallowDirection = DirectionUp
+}
+
 // rank returns a tuple consisting of a series of factors determining which grid cell the segment should try to move into next.
 // These are not absolute rules - rather they are used to rank the four directions in order of preference,
 // i.e. which direction is the best (or at least, least bad) to move in.
@@ -244,8 +254,9 @@ func (s *Segment) rank(proposedOutEdge Direction) int {
 	// We don't want it to to turn back on itself..
 	turningBackOnSelf := proposedOutEdge == s.inEdge
 
-	// ..or go in a direction that's disallowed (see comments in update method)
-	directionDisallowed := proposedOutEdge == s.disallowDirection
+	// ..or go in a direction that's disallowed (see comments in update method).
+	// When diving, the only direction allowed is down.
+	directionDisallowed := proposedOutEdge == s.disallowDirection || (s.diving && proposedOutEdge != DirectionDown)
 
 	// Check to see if there's a rock at the proposed new grid cell.
 	// rock will either be the Rock object at the new grid cell, or nil.
@@ -268,8 +279,11 @@ func (s *Segment) rank(proposedOutEdge Direction) int {
 
 	// Prefer to move horizontally, unless there's a rock in the way.
 	// If there are rocks both horizontally and vertically, prefer to move vertically
+	// A diving segment ignores the horizontal preference
 	var horizontalBlocked bool
-	if rockPresent {
+	if s.diving {
+		horizontalBlocked = false
+	} else if rockPresent {
 		horizontalBlocked = proposedOutEdge.IsHorizontal()
 	} else {
 		horizontalBlocked = !proposedOutEdge.IsHorizontal()
@@ -293,7 +307,7 @@ func (s *Segment) rank(proposedOutEdge Direction) int {
 	if occupiedBySegment {
 		total += 8
 	}
-	if rockPresent {
+	if rockPresent && !s.diving {
 		total += 4
 	}
 	if horizontalBlocked {
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bestDirection returns the direction the segment would choose, as in Segment.update
+func bestDirection(s *Segment) Direction {
+	best := DirectionUp
+	for direction := DirectionUp; direction <= DirectionLeft; direction++ {
+		if s.rank(direction) < s.rank(best) {
+			best = direction
+		}
+	}
+	return best
+}
+
+func TestSegmentRank(t *testing.T) {
+	testCases := []struct {
+		name     string
+		diving   bool
+		rockDown bool
+		rockSide bool
+		expected Direction
+	}{
+		{"prefers horizontal", false, false, false, DirectionRight},
+		{"goes around a rock", false, false, true, DirectionDown},
+		{"diving goes down", true, false, false, DirectionDown},
+		{"diving ignores rocks on the side", true, false, true, DirectionDown},
+		{"diving goes through rocks", true, true, false, DirectionDown},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			game := &Game{}
+			game.newGrid()
+			segment := NewSegment(game, 5, 5, 1, false, true)
+			segment.diving = testCase.diving
+			if testCase.rockDown {
+				game.grid[6][5] = &Rock{}
+			}
+			if testCase.rockSide {
+				game.grid[5][6] = &Rock{}
+			}
+			assert.Equal(t, testCase.expected, bestDirection(segment))
+		})
+	}
+}
+
+func TestPoisonTrail(t *testing.T) {
+	game := &Game{}
+	game.newGrid()
+	game.grid[3][4] = &Rock{}
+	assert.False(t, game.IsPoisoned(4, 3))
+	game.grid[3][4].Poison()
+	assert.True(t, game.IsPoisoned(4, 3))
+	assert.False(t, game.IsPoisoned(-1, 3))
+
+	game.AddPoisonTrail(4, 3)
+	assert.True(t, game.IsPoisonTrail(4, 3))
+	assert.False(t, game.IsPoisonTrail(5, 3))
+	game.time += PoisonTrailTime + 1
+	assert.False(t, game.IsPoisonTrail(4, 3))
+}
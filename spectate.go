@@ -0,0 +1,227 @@
+package main
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/cavern/creativeprojects/myriapod/lib"
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/spectate"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Frame and flags of the objects streamed to the spectators
+const (
+	// players: frame is direction*3 + frame
+	spectateHidden       = 1 << 0
+	spectateInvulnerable = 1 << 1
+	// segments: frame packs fast, health, head, direction and leg frame (see segmentFrame)
+	spectateBoss = 1 << 0
+	// enemies: frame is the kind, 0 to 2 for the flying enemy colours
+	spectateDropper = 3
+	spectateCrawler = 4
+	spectateFlipX   = 1 << 0
+)
+
+// segmentFrame packs the index of the segment image: leg frame on bits 0-1, direction on bits 2-4, then head, health and fast
+func segmentFrame(s *Segment) uint8 {
+	return uint8(s.legFrame) | uint8(s.direction)<<2 | uint8(boolIndex(s.head))<<5 |
+		uint8(boolIndex(s.health >= 2))<<6 | uint8(boolIndex(s.fast))<<7
+}
+
+func spectateObject(x, y float64, frame, flags uint8) spectate.Object {
+	return spectate.Object{X: int16(math.Round(x)), Y: int16(math.Round(y)), Frame: frame, Flags: flags}
+}
+
+// spectateState returns what the spectators see of the game in play
+func (g *Game) spectateState() spectate.State {
+	state := spectate.State{
+		Tick:      g.ticks,
+		Wave:      g.wave,
+		HighScore: g.highScore,
+		Over:      g.state == StateGameOver,
+		Rocks:     make([]spectate.Rock, 0, NumGridCols*NumGridRows),
+	}
+	for _, player := range g.players {
+		state.Scores = append(state.Scores, spectate.Score{Score: player.score, Lives: g.Lives(player)})
+		var flags uint8
+		if !player.alive {
+			flags |= spectateHidden
+		} else if player.timer <= InvulnerabilityTime {
+			flags |= spectateInvulnerable
+		}
+		x, y := player.sprite.X(lib.XCentre), player.sprite.Y(lib.YCentre)
+		state.Players = append(state.Players, spectateObject(x, y, uint8(player.direction*3+player.frame), flags))
+	}
+	for _, segment := range g.segments {
+		var flags uint8
+		if segment.boss {
+			flags = spectateBoss
+		}
+		state.Segments = append(state.Segments, spectateObject(segment.posX, segment.posY, segmentFrame(segment), flags))
+	}
+	for _, bullet := range g.bullets {
+		if !bullet.done {
+			state.Bullets = append(state.Bullets, spectateObject(bullet.sprite.X(lib.XCentre), bullet.sprite.Y(lib.YCentre), 0, 0))
+		}
+	}
+	for _, enemy := range g.enemies {
+		if enemy.IsInactive() {
+			continue
+		}
+		x, y := enemy.Position()
+		switch e := enemy.(type) {
+		case *FlyingEnemy:
+			state.Enemies = append(state.Enemies, spectateObject(x, y, uint8(e.color), 0))
+		case *Dropper:
+			state.Enemies = append(state.Enemies, spectateObject(x, y, spectateDropper, 0))
+		case *Crawler:
+			var flags uint8
+			if e.dx < 0 {
+				flags = spectateFlipX
+			}
+			state.Enemies = append(state.Enemies, spectateObject(x, y, spectateCrawler, flags))
+		}
+	}
+	for _, row := range g.grid {
+		for _, rock := range row {
+			if rock == nil {
+				state.Rocks = append(state.Rocks, spectate.Rock{})
+				continue
+			}
+			state.Rocks = append(state.Rocks, spectate.Rock{
+				Type:     uint8(rock.rockType),
+				Health:   uint8(max(rock.showHealth, 1)),
+				Totem:    rock.isTotem,
+				Poisoned: rock.poisoned,
+			})
+		}
+	}
+	return state
+}
+
+// sendSpectators streams the tick just played
+func (g *Game) sendSpectators() {
+	if g.spectators == nil || g.replaying {
+		return
+	}
+	g.spectators.Send(g.spectateState())
+}
+
+// Spectator shows a game streamed by another instance, read-only
+type Spectator struct {
+	client  *spectate.Client
+	state   spectate.State
+	err     error
+	sprite  *lib.Sprite
+	enemies []*lib.Sprite // by kind, animated here
+}
+
+func NewSpectator(client *spectate.Client) *Spectator {
+	clips := []string{"meanie0", "meanie1", "meanie2", spectateDropper: "dropper", spectateCrawler: "crawler"}
+	enemies := make([]*lib.Sprite, len(clips))
+	for kind, clip := range clips {
+		enemies[kind] = lib.NewSprite(lib.XCentre, lib.YCentre).SetAnimations(animations).Play(clip)
+	}
+	enemies[spectateDropper].SetTint(dropperTint)
+	return &Spectator{
+		client:  client,
+		sprite:  lib.NewSprite(lib.XCentre, lib.YCentre),
+		enemies: enemies,
+	}
+}
+
+// Spectate starts showing the game streamed by the client
+func (g *Game) Spectate(client *spectate.Client) {
+	g.spectator = NewSpectator(client)
+	g.state = StateSpectating
+}
+
+// updateSpectator takes the last state received
+func (g *Game) updateSpectator() {
+	s := g.spectator
+	s.state, s.err = s.client.State()
+	g.wave = s.state.Wave
+	g.highScore = s.state.HighScore
+	for _, enemy := range s.enemies {
+		enemy.Update()
+	}
+	s.enemies[spectateDropper].SetRotation(s.enemies[spectateDropper].Rotation() + 0.2)
+}
+
+func (g *Game) drawSpectator(screen *ebiten.Image) {
+	s := g.spectator
+	sprite := s.sprite
+	colour := max(s.state.Wave, 0) % 3
+	for cell, rock := range s.state.Rocks {
+		if rock.Health == 0 {
+			continue
+		}
+		x, y := CellToPos(cell%NumGridCols, cell/NumGridCols, 0, 0)
+		health := min(int(rock.Health), 5) - 1
+		image := rockImages[colour][rock.Type][health]
+		if rock.Poisoned {
+			image = poisonImages[rock.Type][health]
+		}
+		sprite.MoveTo(x, y).SetImage(image).Draw(screen)
+	}
+	for _, segment := range s.state.Segments {
+		scale := 1.0
+		if segment.Flags&spectateBoss != 0 {
+			scale = BossScale
+		}
+		frame := segment.Frame
+		image := segmentImages[frame>>7&1][frame>>6&1][frame>>5&1][frame>>2&7][frame&3]
+		sprite.MoveTo(float64(segment.X), float64(segment.Y)).SetImage(image).SetScale(scale, scale).Draw(screen)
+	}
+	sprite.SetScale(1, 1)
+	for _, bullet := range s.state.Bullets {
+		sprite.MoveTo(float64(bullet.X), float64(bullet.Y)).SetImage(images["bullet"]).Draw(screen)
+	}
+	for index, player := range s.state.Players {
+		if player.Flags&spectateHidden != 0 || player.Flags&spectateInvulnerable != 0 && (s.state.Tick/2)%2 == 1 {
+			continue
+		}
+		sprite.SetTint(nil)
+		if index > 0 {
+			sprite.SetTint(player2Tint)
+		}
+		image := images["player"+strconv.Itoa(int(player.Frame)/3)+strconv.Itoa(int(player.Frame)%3)]
+		sprite.MoveTo(float64(player.X), float64(player.Y)).SetImage(image).Draw(screen)
+	}
+	sprite.SetTint(nil)
+	for _, enemy := range s.state.Enemies {
+		if int(enemy.Frame) >= len(s.enemies) {
+			continue
+		}
+		enemySprite := s.enemies[enemy.Frame]
+		enemySprite.MoveTo(float64(enemy.X), float64(enemy.Y)).SetFlip(enemy.Flags&spectateFlipX != 0, false).Draw(screen)
+	}
+
+	// same layout as the HUD
+	scores := s.state.Scores
+	if len(scores) == 1 {
+		g.hud.drawLives(screen, scores[0].Lives, false)
+		g.hud.drawScore(screen, scores[0].Score, 448, 5, true)
+	} else if len(scores) == 2 {
+		g.hud.drawLives(screen, scores[0].Lives, false)
+		g.hud.drawScore(screen, scores[0].Score, 8, 40, false)
+		g.hud.drawLives(screen, scores[1].Lives, true)
+		g.hud.drawScore(screen, scores[1].Score, 448, 40, true)
+	}
+	g.hud.drawInfo(screen)
+
+	message := ""
+	switch {
+	case s.err != nil:
+		message = "STREAM ENDED"
+	case s.state.Over:
+		message = "GAME OVER"
+	case len(scores) == 0:
+		message = "WAITING FOR A GAME"
+	}
+	if message != "" {
+		textFont.Draw(screen, message, WindowWidth/2, WindowHeight/2-40, &font.DrawOptions{Align: font.AlignCentre, Scale: 1.5})
+	}
+	textFont.Draw(screen, "SPECTATING", WindowWidth/2, WindowHeight-24, &font.DrawOptions{Align: font.AlignCentre, Scale: 0.5})
+}
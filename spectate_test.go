@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentFrame(t *testing.T) {
+	segment := &Segment{legFrame: 3, direction: 6, head: true, health: 2, fast: true}
+	frame := segmentFrame(segment)
+	// unpacked the same way as the spectator draws it
+	assert.Equal(t, uint8(1), frame>>7&1)
+	assert.Equal(t, uint8(1), frame>>6&1)
+	assert.Equal(t, uint8(1), frame>>5&1)
+	assert.Equal(t, uint8(6), frame>>2&7)
+	assert.Equal(t, uint8(3), frame&3)
+
+	frame = segmentFrame(&Segment{legFrame: 1, direction: 2, health: 1})
+	assert.Equal(t, uint8(0b00001001), frame)
+}
+
+func TestSpectateState(t *testing.T) {
+	game := newNetplayGame()
+	game.wave = 4
+	game.ticks = 100
+	game.grid[2][3] = &Rock{rockType: 2, showHealth: 3, health: 3, poisoned: true}
+	game.segments = append(game.segments, &Segment{posX: 40, posY: 80.4, boss: true})
+	game.players[0].score = 1500
+	game.players[1].alive = false
+
+	state := game.spectateState()
+	assert.Equal(t, 100, state.Tick)
+	assert.Equal(t, 4, state.Wave)
+	require.Len(t, state.Rocks, NumGridCols*NumGridRows)
+	rock := state.Rocks[2*NumGridCols+3]
+	assert.Equal(t, uint8(2), rock.Type)
+	assert.Equal(t, uint8(3), rock.Health)
+	assert.True(t, rock.Poisoned)
+	require.Len(t, state.Segments, 1)
+	assert.Equal(t, int16(40), state.Segments[0].X)
+	assert.Equal(t, int16(80), state.Segments[0].Y)
+	assert.Equal(t, uint8(spectateBoss), state.Segments[0].Flags)
+	require.Len(t, state.Scores, 2)
+	assert.Equal(t, 1500, state.Scores[0].Score)
+	assert.Equal(t, uint8(spectateHidden), state.Players[1].Flags&spectateHidden)
+}
@@ -8,5 +8,7 @@ const (
 	StateMenu GameState = iota
 	StatePlaying
 	StatePaused
+	StateNextPlayer // alternating game: "PLAYER n" screen before the next turn
 	StateGameOver
+	StateSpectating // watching a game streamed by another instance
 )
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/font"
+	"github.com/cavern/creativeprojects/myriapod/lib/versus"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// versusServerCommand runs the server pairing the players of the versus mode. It returns the exit code.
+func versusServerCommand(addr string) int {
+	if addr == "" {
+		addr = VersusAddr
+	}
+	server, err := versus.Listen(addr, VersusVersion)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+	log.Printf("versus server listening on %s", server.Addr())
+	err = server.Serve()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
+
+// updateVersus lands the attacks sent by the opponent, and keeps the opponent informed of the local score
+func (g *Game) updateVersus() {
+	if g.versus == nil {
+		return
+	}
+	for _, attack := range g.versus.Attacks(g.ticks) {
+		g.receiveAttack(attack)
+	}
+
+	opponent := g.versus.Opponent()
+	if opponent.Out && !g.opponent.Out {
+		g.hud.Announce("OPPONENT OUT")
+	}
+	if opponent.Disconnected && !g.opponent.Disconnected {
+		g.hud.Announce("DISCONNECTED")
+	}
+	g.opponent = opponent
+	if opponent.Disconnected {
+		return
+	}
+
+	if g.IsGameOver() {
+		g.versus.SendGameOver(g.ticks, g.players[0].score)
+		return
+	}
+	if g.ticks%VersusStateInterval == 0 {
+		g.versus.SendState(g.ticks, g.players[0].score)
+	}
+}
+
+// updateVersusGameOver keeps sending the final score once the local game is over, until the game is left:
+// the server takes a player who doesn't send anything for a while for disconnected
+func (g *Game) updateVersusGameOver() {
+	if g.versus == nil {
+		return
+	}
+	g.opponent = g.versus.Opponent()
+	if g.opponent.Disconnected {
+		return
+	}
+	g.ticks++
+	if g.ticks%VersusStateInterval == 0 {
+		g.versus.SendGameOver(g.ticks, g.players[0].score)
+	}
+}
+
+// sendAttack to the opponent, if still there
+func (g *Game) sendAttack(kind versus.AttackKind, count int) {
+	if g.versus == nil || g.opponent.Disconnected {
+		return
+	}
+	g.versus.SendAttack(g.ticks, kind, count)
+}
+
+// versusSegmentKilled sends rocks to the opponent every few segments killed
+func (g *Game) versusSegmentKilled() {
+	g.versusKills++
+	if g.versusKills%VersusKillsPerAttack == 0 {
+		g.sendAttack(versus.AttackRocks, VersusRocksPerAttack)
+	}
+}
+
+// receiveAttack drops rocks or extra segments onto the local board
+func (g *Game) receiveAttack(attack versus.Attack) {
+	switch attack.Kind {
+	case versus.AttackRocks:
+		for i := 0; i < attack.Count; i++ {
+			// a few tries to find an empty cell, away from the top row and the players
+			for try := 0; try < 10; try++ {
+				cellX, cellY := g.random.Intn(NumGridCols), 1+g.random.Intn(NumGridRows-3)
+				if g.grid[cellY][cellX] == nil && g.AllowRock(cellX, cellY) {
+					g.grid[cellY][cellX] = NewRock(g, cellX, cellY, false)
+					break
+				}
+			}
+		}
+		g.SoundEffect("rock_create0")
+	case versus.AttackSegments:
+		for i := 0; i < attack.Count; i++ {
+			g.segments = append(g.segments, NewSegment(g, -1-i, 0, 1, false, i == 0))
+		}
+		g.SoundEffect("wave0")
+	}
+}
+
+// drawVersus shows the opponent's score under the local score
+func (g *Game) drawVersus(screen *ebiten.Image) {
+	if g.versus == nil {
+		return
+	}
+	text := "VS " + strconv.Itoa(g.opponent.Score)
+	if g.opponent.Disconnected {
+		text = "VS --"
+	} else if g.opponent.Out {
+		text += " OUT"
+	}
+	textFont.Draw(screen, text, WindowWidth-8, 40, &font.DrawOptions{Align: font.AlignRight, Scale: 0.5})
+}
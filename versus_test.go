@@ -0,0 +1,100 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cavern/creativeprojects/myriapod/lib/versus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersusTestGames starts two headless games connected through a local versus server
+func newVersusTestGames(t *testing.T) []*Game {
+	t.Helper()
+	require.NoError(t, loadTestResources())
+	server, err := versus.Listen("127.0.0.1:0", VersusVersion)
+	require.NoError(t, err)
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+
+	clients := make([]*versus.Client, 2)
+	errs := make([]error, 2)
+	wg := sync.WaitGroup{}
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], errs[i] = versus.Dial(server.Addr().String(), VersusVersion, time.Second)
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+
+	games := make([]*Game, 2)
+	for i, client := range clients {
+		games[i] = NewHeadlessGame()
+		games[i].versus = client
+		games[i].Start(1, false)
+		// plenty of rocks: the first wave starts straight away, and no rock is added while it's on
+		for y := 1; y < 10; y++ {
+			for x := 0; x < NumGridCols; x++ {
+				if games[i].grid[y][x] == nil {
+					games[i].grid[y][x] = NewRock(games[i], x, y, false)
+				}
+			}
+		}
+	}
+	t.Cleanup(func() {
+		for _, game := range games {
+			game.Initialize()
+		}
+	})
+	return games
+}
+
+func TestVersusAttacks(t *testing.T) {
+	games := newVersusTestGames(t)
+	// both boards start the same
+	assert.Equal(t, games[0].StateHash(), games[1].StateHash())
+
+	for _, game := range games {
+		game.step()
+	}
+	segments := len(games[1].segments)
+	rocks := games[0].RockCount()
+	require.Greater(t, segments, 0)
+
+	games[0].sendAttack(versus.AttackSegments, 3)
+	games[1].sendAttack(versus.AttackRocks, 2)
+	for tick := 0; tick <= versus.AttackDelay; tick++ {
+		for _, game := range games {
+			game.step()
+		}
+		// leave time for the attacks to travel
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	assert.Len(t, games[1].segments, segments+3)
+	assert.Equal(t, rocks+2, games[0].RockCount())
+}
+
+func TestVersusGameOverKeepsConnection(t *testing.T) {
+	games := newVersusTestGames(t)
+	games[0].state = StateGameOver
+	games[0].players[0].score = 1234
+
+	// longer than the server timeout, with the local game over
+	deadline := time.Now().Add(versus.Timeout + time.Second)
+	for time.Now().Before(deadline) {
+		games[0].updateVersusGameOver()
+		games[1].step()
+		time.Sleep(time.Second / GameNormalSpeed)
+	}
+	games[1].step()
+	assert.True(t, games[1].opponent.Out)
+	assert.False(t, games[1].opponent.Disconnected)
+	assert.Equal(t, 1234, games[1].opponent.Score)
+}
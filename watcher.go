@@ -0,0 +1,146 @@
+//go:build !prod
+
+package main
+
+import (
+	"image"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// WatchInterval is how often the asset directory is scanned for changes
+const WatchInterval = 500 * time.Millisecond
+
+var assetWatcher *AssetWatcher
+
+// AssetWatcher polls a local copy of the images and sounds folders, and decodes any file that changed.
+// Decoded assets are kept aside until the game swaps them in between two frames.
+type AssetWatcher struct {
+	fsys     fs.FS
+	modTimes map[string]time.Time
+	mu       sync.Mutex
+	images   map[string]image.Image
+	sounds   map[string][]byte
+	done     chan struct{}
+}
+
+// WatchAssets starts watching dir, which should contain the "images" and "sounds" folders
+func WatchAssets(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	w := &AssetWatcher{
+		fsys:     os.DirFS(dir),
+		modTimes: make(map[string]time.Time),
+		images:   make(map[string]image.Image),
+		sounds:   make(map[string][]byte),
+		done:     make(chan struct{}),
+	}
+	// first scan only records the current state of the files
+	w.scan(false)
+	go w.run()
+	assetWatcher = w
+	log.Printf("watching assets in %s", dir)
+	return nil
+}
+
+// Close stops the watcher
+func (w *AssetWatcher) Close() {
+	close(w.done)
+}
+
+func (w *AssetWatcher) run() {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.scan(true)
+		}
+	}
+}
+
+func (w *AssetWatcher) scan(reload bool) {
+	imageNames, _ := fs.Glob(w.fsys, "images/*.png")
+	for _, imageName := range imageNames {
+		if !w.changed(imageName) || !reload {
+			continue
+		}
+		img, err := readImage(w.fsys, imageName)
+		if err != nil {
+			// the file might still be in the middle of being saved: try again on next scan
+			log.Print(err)
+			delete(w.modTimes, imageName)
+			continue
+		}
+		w.mu.Lock()
+		w.images[assetName(imageName)] = img
+		w.mu.Unlock()
+	}
+
+	soundNames, _ := fs.Glob(w.fsys, "sounds/*.ogg")
+	for _, soundName := range soundNames {
+		if !w.changed(soundName) || !reload {
+			continue
+		}
+		buf, err := readSound(w.fsys, soundName)
+		if err != nil {
+			log.Print(err)
+			delete(w.modTimes, soundName)
+			continue
+		}
+		w.mu.Lock()
+		w.sounds[assetName(soundName)] = buf
+		w.mu.Unlock()
+	}
+}
+
+// changed records the modification time of the file and returns true if it's different from the last scan
+func (w *AssetWatcher) changed(fileName string) bool {
+	info, err := fs.Stat(w.fsys, fileName)
+	if err != nil {
+		return false
+	}
+	previous, found := w.modTimes[fileName]
+	w.modTimes[fileName] = info.ModTime()
+	return !found || !previous.Equal(info.ModTime())
+}
+
+// swap moves the pending assets into the images and sounds maps. It returns true if any image was replaced.
+// It must be called from the game loop, between two frames.
+func (w *AssetWatcher) swap() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for name, buf := range w.sounds {
+		log.Printf("reloading sound %s", name)
+		sounds[name] = buf
+		delete(w.sounds, name)
+	}
+	if len(w.images) == 0 {
+		return false
+	}
+	for name, img := range w.images {
+		log.Printf("reloading image %s", name)
+		images[name] = ebiten.NewImageFromImage(img)
+		delete(w.images, name)
+	}
+	return true
+}
+
+// reloadAssets swaps in any asset changed on disk since the last frame
+func (g *Game) reloadAssets() {
+	if assetWatcher == nil {
+		return
+	}
+	if assetWatcher.swap() {
+		g.refreshImages()
+	}
+}
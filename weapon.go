@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// Projectile is one bullet of a shot, relative to the player's gun
+type Projectile struct {
+	OffsetX float64 // horizontal distance from the gun
+	DX      float64 // sideways movement per frame
+}
+
+// Shot holds everything a bullet needs to know when it's fired
+type Shot struct {
+	DX     float64
+	Speed  float64
+	Damage int
+	Pierce int // number of segments the bullet goes through before stopping
+	Scale  float64
+	Colour color.RGBA
+}
+
+// Weapon defines the bullets fired by the player
+type Weapon struct {
+	Name     string
+	Pattern  []Projectile // bullets fired in one shot
+	Speed    float64      // pixels per frame, upwards
+	Damage   int          // against rocks and segments. For a charged weapon, this is the damage at full charge
+	Pierce   int          // number of segments a bullet goes through before stopping
+	FireRate int          // ticks between two frames of the firing animation: a shot is fired every three frames
+	Charge   int          // ticks to hold the fire button for a full charge. The shot is released with the button
+	Colour   color.RGBA
+}
+
+var (
+	// Cannon is the default weapon
+	Cannon = Weapon{
+		Name:     "cannon",
+		Pattern:  []Projectile{{}},
+		Speed:    24,
+		Damage:   1,
+		FireRate: ReloadTime,
+		Colour:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+	}
+	// SpreadShot fires three bullets in a fan
+	SpreadShot = Weapon{
+		Name:     "spread",
+		Pattern:  []Projectile{{DX: -SpreadShotAngle}, {}, {DX: SpreadShotAngle}},
+		Speed:    24,
+		Damage:   1,
+		FireRate: ReloadTime,
+		Colour:   color.RGBA{0x60, 0xc0, 0xff, 0xff},
+	}
+	// LaserBeam fires a continuous stream of fast bullets going through the segments
+	LaserBeam = Weapon{
+		Name:     "laser",
+		Pattern:  []Projectile{{}},
+		Speed:    32,
+		Damage:   1,
+		Pierce:   10,
+		FireRate: 0,
+		Colour:   color.RGBA{0xff, 0x60, 0x60, 0xff},
+	}
+	// ChargeShot fires a bigger bullet the longer the fire button is held
+	ChargeShot = Weapon{
+		Name:     "charge",
+		Pattern:  []Projectile{{}},
+		Speed:    20,
+		Damage:   4,
+		Pierce:   2,
+		FireRate: ReloadTime,
+		Charge:   60,
+		Colour:   color.RGBA{0xff, 0xd0, 0x40, 0xff},
+	}
+)
+
+// Weapons by name
+var Weapons = map[string]*Weapon{
+	Cannon.Name:     &Cannon,
+	SpreadShot.Name: &SpreadShot,
+	LaserBeam.Name:  &LaserBeam,
+	ChargeShot.Name: &ChargeShot,
+}
+
+// WithPowerUps returns the weapon modified by the power-ups currently active
+func (w Weapon) WithPowerUps(powerUps [numPowerUps]int) Weapon {
+	if powerUps[PowerUpRapidFire] > 0 && w.FireRate > RapidFireReloadTime {
+		w.FireRate = RapidFireReloadTime
+	}
+	if powerUps[PowerUpSpread] > 0 && len(w.Pattern) == 1 {
+		w.Pattern = SpreadShot.Pattern
+	}
+	if powerUps[PowerUpPiercing] > 0 {
+		w.Pierce += PiercingPowerUpCount
+	}
+	return w
+}
+
+// Shots returns the bullets fired, with charge between 0 and 1 (only used by a charged weapon)
+func (w Weapon) Shots(charge float64) []Shot {
+	damage := w.Damage
+	scale := 1.0
+	if w.Charge > 0 {
+		charge = math.Max(0, math.Min(charge, 1))
+		damage = 1 + int(charge*float64(w.Damage-1))
+		scale += charge
+	}
+	shots := make([]Shot, len(w.Pattern))
+	for i, projectile := range w.Pattern {
+		shots[i] = Shot{
+			DX:     projectile.DX,
+			Speed:  w.Speed,
+			Damage: damage,
+			Pierce: w.Pierce,
+			Scale:  scale,
+			Colour: w.Colour,
+		}
+	}
+	return shots
+}
+
+// Fire all the bullets of a shot from the player's gun at x, y
+func (w Weapon) Fire(player *Player, x, y, charge float64) {
+	for i, shot := range w.Shots(charge) {
+		player.game.Fire(player, x+w.Pattern[i].OffsetX, y, shot)
+	}
+}
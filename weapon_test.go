@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeaponShots(t *testing.T) {
+	testCases := []struct {
+		name   string
+		weapon Weapon
+		charge float64
+		count  int
+		damage int
+		scale  float64
+	}{
+		{"cannon", Cannon, 0, 1, 1, 1},
+		{"spread", SpreadShot, 0, 3, 1, 1},
+		{"laser", LaserBeam, 0, 1, 1, 1},
+		{"charge empty", ChargeShot, 0, 1, 1, 1},
+		{"charge half", ChargeShot, 0.5, 1, 2, 1.5},
+		{"charge full", ChargeShot, 1, 1, ChargeShot.Damage, 2},
+		{"charge overflow", ChargeShot, 3, 1, ChargeShot.Damage, 2},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			shots := testCase.weapon.Shots(testCase.charge)
+			assert.Len(t, shots, testCase.count)
+			for _, shot := range shots {
+				assert.Equal(t, testCase.damage, shot.Damage)
+				assert.Equal(t, testCase.scale, shot.Scale)
+				assert.Equal(t, testCase.weapon.Speed, shot.Speed)
+				assert.Equal(t, testCase.weapon.Pierce, shot.Pierce)
+			}
+		})
+	}
+}
+
+func TestWeaponWithPowerUps(t *testing.T) {
+	powerUps := [numPowerUps]int{}
+	assert.Equal(t, Cannon, Cannon.WithPowerUps(powerUps))
+
+	powerUps[PowerUpRapidFire] = 1
+	powerUps[PowerUpSpread] = 1
+	powerUps[PowerUpPiercing] = 1
+	weapon := Cannon.WithPowerUps(powerUps)
+	assert.Equal(t, RapidFireReloadTime, weapon.FireRate)
+	assert.Len(t, weapon.Pattern, 3)
+	assert.Equal(t, PiercingPowerUpCount, weapon.Pierce)
+	assert.Len(t, Cannon.Pattern, 1, "the original weapon should not change")
+
+	// the laser already fires faster than the rapid fire
+	weapon = LaserBeam.WithPowerUps(powerUps)
+	assert.Equal(t, 0, weapon.FireRate)
+	assert.Equal(t, LaserBeam.Pierce+PiercingPowerUpCount, weapon.Pierce)
+}
+
+func TestSpreadShotFromTheRightEdge(t *testing.T) {
+	game := newHeadlessTestGame(t, 1, 1)
+	assert.False(t, game.Damage(NumGridCols, 0, 1, nil))
+	assert.False(t, game.Damage(0, NumGridRows, 1, nil))
+
+	player := game.players[0]
+	bullets := make([]*Bullet, 0, 3)
+	for _, shot := range SpreadShot.Shots(0) {
+		bullet := NewBullet(game)
+		bullet.Start(player, PlayerMaxX, PlayerMinY, shot)
+		bullets = append(bullets, bullet)
+	}
+	for tick := 0; tick < WindowHeight; tick++ {
+		for _, bullet := range bullets {
+			require.NotPanics(t, bullet.Update)
+		}
+	}
+	for _, bullet := range bullets {
+		assert.True(t, bullet.done)
+	}
+}